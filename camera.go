@@ -0,0 +1,157 @@
+package main
+
+import (
+	"math"
+
+	"github.com/braheezy/hobby-spline/pkg/bezier"
+	"github.com/braheezy/hobby-spline/pkg/scene"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// camera maps world coordinates, which knots and curves are in, to screen
+// pixels.
+type camera struct {
+	// offsetX and offsetY are the screen position of the world origin
+	offsetX float64
+	offsetY float64
+	// zoom is the number of screen pixels per world unit
+	zoom float64
+}
+
+// toScreen returns the screen position of a point in the world.
+func (c camera) toScreen(p bezier.Point) bezier.Point {
+	return bezier.Point{X: p.X*c.zoom + c.offsetX, Y: p.Y*c.zoom + c.offsetY}
+}
+
+// toWorld returns the point in the world at a screen position.
+func (c camera) toWorld(x, y float64) bezier.Point {
+	return bezier.Point{X: (x - c.offsetX) / c.zoom, Y: (y - c.offsetY) / c.zoom}
+}
+
+// curve returns the Bézier curve through the screen positions of points.
+func (c camera) curve(points []bezier.Point) *bezier.Bezier {
+	moved := make([]bezier.Point, len(points))
+	for i, p := range points {
+		moved[i] = c.toScreen(p)
+	}
+	curve, _ := bezier.NewBezier(false, moved...)
+	return curve
+}
+
+// pan moves the view by dx, dy screen pixels.
+func (c *camera) pan(dx, dy float64) {
+	c.offsetX += dx
+	c.offsetY += dy
+}
+
+// zoomAt zooms by factor, keeping the world point at x, y on screen where it
+// is.
+func (c *camera) zoomAt(x, y, factor float64) {
+	zoom := math.Max(minZoom, math.Min(maxZoom, c.zoom*factor))
+	anchor := c.toWorld(x, y)
+	c.zoom = zoom
+	c.offsetX = x - anchor.X*zoom
+	c.offsetY = y - anchor.Y*zoom
+}
+
+// fitCamera returns a camera that centers the bounding box of points in the
+// canvas above the toolbar, leaving a margin for the comb.
+func fitCamera(points []bezier.Point) camera {
+	if len(points) == 0 {
+		return camera{zoom: 1}
+	}
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range points {
+		minX, maxX = math.Min(minX, p.X), math.Max(maxX, p.X)
+		minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+	}
+
+	margin := float64(4 * padding)
+	width, height := screenWidth-2*margin, screenHeight-toolbarHeight-2*margin
+	zoom := math.Min(width/math.Max(maxX-minX, 1), height/math.Max(maxY-minY, 1))
+	return camera{
+		offsetX: margin + (width-(maxX-minX)*zoom)/2 - minX*zoom,
+		offsetY: margin + (height-(maxY-minY)*zoom)/2 - minY*zoom,
+		zoom:    zoom,
+	}
+}
+
+// updateCamera pans and zooms the view. Pans are made by dragging with the
+// middle mouse button, with the left one while Space is held, or with two
+// fingers, which also zoom by pinching. The mouse wheel zooms around the
+// cursor.
+//
+// It reports whether the input was used to pan or zoom, in which case it
+// shouldn't also edit the curve.
+func (g *Game) updateCamera() bool {
+	x, y := g.pointers.cursorX, g.pointers.cursorY
+	if _, wheel := ebiten.Wheel(); wheel != 0 {
+		g.camera.zoomAt(float64(x), float64(y), math.Pow(wheelZoomFactor, wheel))
+	}
+
+	// A second finger starts a pinch, unless it lands on a knot while the
+	// others are dragging ones already there, in which case they're all
+	// dragged together. Either way, the pinch ends whatever the first
+	// finger started.
+	touches := g.pointers.touches()
+	if n := len(touches); n >= 2 && !g.panning && touches[n-1].justPressed {
+		p := touches[n-1]
+		if g.path().knotAt(g.camera, p.x, p.y) < 0 || !g.draggingExisting() {
+			g.cancelDrag()
+			g.panning = true
+		}
+	}
+	if g.panning && len(touches) >= 2 {
+		// Pan with the midpoint of the first two fingers, and zoom with the
+		// distance between them
+		dx, dy, cx, cy, scale := pinch(touches[0], touches[1])
+		g.camera.pan(dx, dy)
+		g.camera.zoomAt(cx, cy, scale)
+		return true
+	}
+
+	spaceDrag := ebiten.IsKeyPressed(ebiten.KeySpace) && ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
+	if (spaceDrag || ebiten.IsMouseButtonPressed(ebiten.MouseButtonMiddle)) && !g.dragging() && g.ui.active == "" {
+		if g.panning {
+			g.camera.pan(float64(x-g.panX), float64(y-g.panY))
+		}
+		g.panning = true
+		g.panX, g.panY = x, y
+		return true
+	}
+
+	// Once panning, the rest of the gesture isn't an edit
+	if g.panning {
+		if len(g.pointers.down) == 0 && !ebiten.IsMouseButtonPressed(ebiten.MouseButtonMiddle) {
+			g.panning = false
+		}
+		return true
+	}
+	return false
+}
+
+// fitToContent zooms and pans the view to fit whatever is being shown.
+func (g *Game) fitToContent() {
+	var points []bezier.Point
+	switch {
+	case g.freehand:
+		points = append(points, g.stroke...)
+		points = append(points, g.fittedPoints...)
+	case g.glyphMode:
+		for _, c := range g.glyphContours {
+			points = append(points, c.Spline()...)
+		}
+	default:
+		for _, p := range g.paths {
+			if len(p.spline) != 0 {
+				points = append(points, p.spline...)
+			} else {
+				points = append(points, scene.Points(p.Points)...)
+			}
+		}
+	}
+	if len(points) != 0 {
+		g.camera = fitCamera(points)
+	}
+}
@@ -0,0 +1,240 @@
+// Command spline fits a spline through points read from a file or standard
+// input, and writes it out without opening a window. It's meant for
+// generating curves in batch, e.g. from a build script.
+//
+// Usage:
+//
+//	spline [flags] [file]
+//
+// Points are read as CSV, one "x,y" pair per line, or as a JSON array of
+// [x, y] pairs or {"x": ..., "y": ...} objects. With no file, or a file of
+// "-", points are read from standard input.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"image/color"
+	"io"
+	"log"
+	"math"
+	"os"
+	"strconv"
+
+	"github.com/braheezy/hobby-spline/pkg/bezier"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("spline: ")
+
+	omega := flag.Float64("omega", 0.75, "curl at the end points of a Hobby spline, between 0 and 1")
+	algorithm := flag.String("algorithm", "hobby", "how to fit the spline: hobby or natural")
+	closed := flag.Bool("closed", false, "join the last point back to the first")
+	inputFormat := flag.String("input", "", "format of the input: csv or json (default: detected)")
+	format := flag.String("format", "points", "output format: points (CSV), json, svg, png, eps, pdf or gcode")
+	output := flag.String("o", "", "write to this file instead of standard output")
+	width := flag.Float64("width", 0, "width of the image, keeping X coordinates as they are (default: fit the curve, moving it)")
+	height := flag.Float64("height", 0, "height of the image, keeping Y coordinates as they are (default: fit the curve, moving it)")
+	margin := flag.Float64("margin", 10, "space around the curve when fitting the image to it")
+	strokeWidth := flag.Float64("stroke-width", 2, "width of the curve in images")
+	stroke := flag.String("color", "#000000", "color of the curve in images")
+	background := flag.String("background", "", "background color of images (default: transparent)")
+	knots := flag.Bool("knots", false, "mark the input points in images")
+	tolerance := flag.Float64("tolerance", 0.1, "furthest a G-code toolpath may stray from the curve, in output units")
+	arcs := flag.Bool("arcs", false, "use G2/G3 arcs in G-code instead of G1 lines")
+	scale := flag.Float64("scale", 1, "G-code output units per input unit")
+	flipY := flag.Bool("flip-y", false, "flip G-code Y to point up, with the origin at the bottom of the image height")
+	inches := flag.Bool("inches", false, "write G-code in inches instead of millimeters")
+	feed := flag.Float64("feed", 0, "G-code feed rate of drawing moves, in output units per minute")
+	penUp := flag.String("pen-up", "", "G-code command(s) that lift the pen")
+	penDown := flag.String("pen-down", "", "G-code command(s) that lower the pen")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [file]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	points, err := readInput(flag.Arg(0), *inputFormat)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	spline, err := createSpline(points, *algorithm, *omega, *closed)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	curveColor, err := parseColor(*stroke)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var backgroundColor color.Color
+	if *background != "" {
+		if backgroundColor, err = parseColor(*background); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Drawings are moved to fit the image, unless its size is given
+	offset := fitImage(spline, width, height, *margin)
+	drawn := translate(spline, offset)
+
+	renderOpts := bezier.RenderOptions{
+		Width:      *width,
+		Height:     *height,
+		Background: backgroundColor,
+		CurveColor: curveColor,
+		CurveWidth: *strokeWidth,
+	}
+	if *knots {
+		renderOpts.Knots = translate(points, offset)
+		renderOpts.KnotColor = curveColor
+		renderOpts.KnotRadius = 2 * *strokeWidth
+	}
+	printOpts := bezier.PrintOptions{
+		Width:       *width,
+		Height:      *height,
+		Background:  backgroundColor,
+		StrokeColor: curveColor,
+		StrokeWidth: *strokeWidth,
+	}
+
+	gcodeOpts := bezier.GCodeOptions{
+		Tolerance: *tolerance,
+		Arcs:      *arcs,
+		Scale:     *scale,
+		FeedRate:  *feed,
+		PenUp:     *penUp,
+		PenDown:   *penDown,
+	}
+	if *flipY {
+		gcodeOpts.Height = *height
+	}
+	if *inches {
+		gcodeOpts.Units = bezier.Inches
+	}
+
+	var buf bytes.Buffer
+	switch *format {
+	case "points":
+		err = writeCSV(&buf, spline)
+	case "json":
+		err = writeJSON(&buf, spline)
+	case "svg":
+		err = bezier.WriteSVG(&buf, drawn, renderOpts)
+	case "png":
+		err = bezier.WritePNG(&buf, drawn, renderOpts)
+	case "eps":
+		err = bezier.WriteEPS(&buf, drawn, printOpts)
+	case "pdf":
+		err = bezier.WritePDF(&buf, drawn, printOpts)
+	case "gcode":
+		err = bezier.WriteGCode(&buf, [][]bezier.Point{drawn}, gcodeOpts)
+	default:
+		err = fmt.Errorf("unknown output format %q", *format)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+	} else {
+		err = os.WriteFile(*output, buf.Bytes(), 0o644)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// readInput reads points from the named file, or standard input if name is
+// empty or "-".
+func readInput(name string, format string) ([]bezier.Point, error) {
+	var r io.Reader = os.Stdin
+	if name != "" && name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	return readPoints(bufio.NewReader(r), format)
+}
+
+// createSpline fits a spline through points with the named algorithm.
+func createSpline(points []bezier.Point, algorithm string, omega float64, closed bool) ([]bezier.Point, error) {
+	switch algorithm {
+	case "hobby":
+		if closed {
+			return bezier.CreateClosedHobbySpline(points)
+		}
+		return bezier.CreateHobbySpline(points, omega)
+	case "natural":
+		if closed {
+			return nil, errors.New("closed natural splines are not supported")
+		}
+		return bezier.NaturalCubicSpline(points)
+	}
+	return nil, fmt.Errorf("unknown algorithm %q", algorithm)
+}
+
+// fitImage fits the width and height of an image to the spline, handles
+// included, where they're zero, leaving margin around it. It returns how far
+// the spline must move to fit: along a fitted axis, the image starts margin
+// before the spline, and along the others the coordinates are used as-is.
+func fitImage(spline []bezier.Point, width, height *float64, margin float64) bezier.Point {
+	minP := bezier.Point{X: math.Inf(1), Y: math.Inf(1)}
+	maxP := bezier.Point{X: math.Inf(-1), Y: math.Inf(-1)}
+	for _, p := range spline {
+		minP.X, maxP.X = min(minP.X, p.X), max(maxP.X, p.X)
+		minP.Y, maxP.Y = min(minP.Y, p.Y), max(maxP.Y, p.Y)
+	}
+
+	var offset bezier.Point
+	if *width == 0 {
+		*width = math.Ceil(maxP.X - minP.X + 2*margin)
+		offset.X = margin - minP.X
+	}
+	if *height == 0 {
+		*height = math.Ceil(maxP.Y - minP.Y + 2*margin)
+		offset.Y = margin - minP.Y
+	}
+	return offset
+}
+
+// translate returns the points moved by offset.
+func translate(points []bezier.Point, offset bezier.Point) []bezier.Point {
+	moved := make([]bezier.Point, len(points))
+	for i, p := range points {
+		moved[i] = bezier.Point{X: p.X + offset.X, Y: p.Y + offset.Y}
+	}
+	return moved
+}
+
+// parseColor parses a color in #rrggbb notation.
+func parseColor(s string) (color.Color, error) {
+	var r, g, b uint8
+	if len(s) != 7 || s[0] != '#' {
+		return nil, fmt.Errorf("invalid color %q, expected #rrggbb", s)
+	}
+	if _, err := fmt.Sscanf(s, "#%02x%02x%02x", &r, &g, &b); err != nil {
+		return nil, fmt.Errorf("invalid color %q, expected #rrggbb", s)
+	}
+	return color.RGBA{R: r, G: g, B: b, A: 0xff}, nil
+}
+
+// formatNumber formats a coordinate for the text outputs, using as few digits
+// as needed to read it back exactly.
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/braheezy/hobby-spline/pkg/bezier"
+)
+
+func TestFitImage(t *testing.T) {
+	spline := []bezier.Point{{X: -20, Y: 10}, {X: 0, Y: -70.5}, {X: 100, Y: 0}, {X: 150, Y: 80}}
+	tests := []struct {
+		name          string
+		width, height float64
+		// want are the size of the image and where the spline moves to
+		wantWidth, wantHeight float64
+		wantOffset            bezier.Point
+	}{
+		{name: "fitted", wantWidth: 190, wantHeight: 171, wantOffset: bezier.Point{X: 30, Y: 80.5}},
+		{name: "width given", width: 500, wantWidth: 500, wantHeight: 171, wantOffset: bezier.Point{Y: 80.5}},
+		{name: "both given", width: 500, height: 300, wantWidth: 500, wantHeight: 300},
+	}
+	for _, tc := range tests {
+		width, height := tc.width, tc.height
+		offset := fitImage(spline, &width, &height, 10)
+		if width != tc.wantWidth || height != tc.wantHeight || offset != tc.wantOffset {
+			t.Errorf("%s: image %gx%g moving the spline by %v, want %gx%g moving it by %v",
+				tc.name, width, height, offset, tc.wantWidth, tc.wantHeight, tc.wantOffset)
+		}
+		// Fitted axes leave the margin around the spline
+		for _, p := range translate(spline, offset) {
+			if tc.width == 0 && (p.X < 10 || p.X > width-10) || tc.height == 0 && (p.Y < 10 || p.Y > height-10) {
+				t.Errorf("%s: %v is within the margin of the %gx%g image", tc.name, p, width, height)
+			}
+		}
+	}
+}
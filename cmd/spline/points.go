@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/braheezy/hobby-spline/pkg/bezier"
+)
+
+// readPoints reads points in the given format, csv or json. If format is
+// empty, input starting with '[' is taken to be JSON and anything else CSV.
+func readPoints(r *bufio.Reader, format string) ([]bezier.Point, error) {
+	if format == "" {
+		format = "csv"
+		for {
+			c, _, err := r.ReadRune()
+			if err != nil {
+				break
+			}
+			if !unicode.IsSpace(c) {
+				if c == '[' {
+					format = "json"
+				}
+				r.UnreadRune()
+				break
+			}
+		}
+	}
+
+	switch format {
+	case "csv":
+		return readCSV(r)
+	case "json":
+		return readJSON(r)
+	}
+	return nil, fmt.Errorf("unknown input format %q", format)
+}
+
+// readCSV reads one point per record from the first two fields, ignoring
+// comment lines starting with '#' and a header line if there is one.
+func readCSV(r io.Reader) ([]bezier.Point, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	var points []bezier.Point
+	for first := true; ; first = false {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		if len(record) < 2 {
+			return nil, fmt.Errorf("line %d: expected x and y, got %d field(s)", line, len(record))
+		}
+
+		x, errX := strconv.ParseFloat(strings.TrimSpace(record[0]), 64)
+		y, errY := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if errX != nil || errY != nil {
+			if first {
+				// Assume it's a header
+				continue
+			}
+			return nil, fmt.Errorf("line %d: invalid point %q", line, strings.Join(record, ","))
+		}
+		points = append(points, bezier.Point{X: x, Y: y})
+	}
+	return points, nil
+}
+
+// readJSON reads an array whose elements are either [x, y] pairs or objects
+// with x and y keys.
+func readJSON(r io.Reader) ([]bezier.Point, error) {
+	var elements []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&elements); err != nil {
+		return nil, err
+	}
+
+	points := make([]bezier.Point, len(elements))
+	for i, element := range elements {
+		var pair []float64
+		if err := json.Unmarshal(element, &pair); err == nil {
+			if len(pair) < 2 {
+				return nil, fmt.Errorf("point %d: expected [x, y]", i)
+			}
+			points[i] = bezier.Point{X: pair[0], Y: pair[1]}
+			continue
+		}
+
+		var object struct {
+			X *float64 `json:"x"`
+			Y *float64 `json:"y"`
+		}
+		if err := json.Unmarshal(element, &object); err != nil || object.X == nil || object.Y == nil {
+			return nil, fmt.Errorf("point %d: expected [x, y] or {\"x\": ..., \"y\": ...}", i)
+		}
+		points[i] = bezier.Point{X: *object.X, Y: *object.Y}
+	}
+	if len(points) == 0 {
+		return nil, errors.New("no points")
+	}
+	return points, nil
+}
+
+// writeCSV writes every point of the spline, knots and handles alike, as an
+// "x,y" line.
+func writeCSV(w io.Writer, spline []bezier.Point) error {
+	bw := bufio.NewWriter(w)
+	for _, p := range spline {
+		fmt.Fprintf(bw, "%s,%s\n", formatNumber(p.X), formatNumber(p.Y))
+	}
+	return bw.Flush()
+}
+
+// writeJSON writes every point of the spline as an array of [x, y] pairs.
+func writeJSON(w io.Writer, spline []bezier.Point) error {
+	pairs := make([][2]float64, len(spline))
+	for i, p := range spline {
+		pairs[i] = [2]float64{p.X, p.Y}
+	}
+	enc := json.NewEncoder(w)
+	return enc.Encode(pairs)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/braheezy/hobby-spline/pkg/bezier"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+)
+
+// glyphFont is parsed the first time glyph mode is entered.
+var glyphFont *sfnt.Font
+
+// loadGlyph loads the outline of r, fitted to the canvas, and re-fits each of
+// its contours with a closed Hobby spline.
+func (g *Game) loadGlyph(r rune) error {
+	if glyphFont == nil {
+		f, err := sfnt.Parse(goregular.TTF)
+		if err != nil {
+			return err
+		}
+		glyphFont = f
+	}
+
+	contours, err := bezier.LoadGlyph(glyphFont, r, float64(glyphFont.UnitsPerEm()))
+	if err != nil {
+		return err
+	}
+
+	var points []bezier.Point
+	for _, c := range contours {
+		for _, seg := range c.Segments {
+			points = append(points, seg.Points...)
+		}
+	}
+	fit := fitCamera(points)
+	for i, c := range contours {
+		for j, seg := range c.Segments {
+			moved := make([]bezier.Point, len(seg.Points))
+			for k, p := range seg.Points {
+				moved[k] = fit.toScreen(p)
+			}
+			contours[i].Segments[j], _ = bezier.NewBezier(false, moved...)
+		}
+	}
+
+	g.glyphRune = r
+	g.glyphContours = contours
+	g.glyphRefits = nil
+	for _, c := range contours {
+		// Contours with too few points to re-fit are still shown as designed
+		refit, _ := c.Refit()
+		g.glyphRefits = append(g.glyphRefits, refit)
+	}
+	return nil
+}
+
+// stepGlyph shows the glyph step places after the one shown. Glyphs without
+// an outline, like space, are skipped.
+func (g *Game) stepGlyph(step rune) {
+	r := g.glyphRune
+	for i := 0; i <= maxGlyph-minGlyph; i++ {
+		r += step
+		if r > maxGlyph {
+			r = minGlyph
+		} else if r < minGlyph {
+			r = maxGlyph
+		}
+		if err := g.loadGlyph(r); err == nil && len(g.glyphContours) != 0 {
+			return
+		}
+	}
+}
+
+func (g *Game) drawGlyph(screen *ebiten.Image) {
+	// Draw the outline as designed
+	strokeOp := &vector.StrokeOptions{Width: 1}
+	onCurve := 0
+	for _, c := range g.glyphContours {
+		for _, seg := range c.Segments {
+			strokeCurve(screen, g.camera.curve(seg.Points), strokeOp, naturalCurveColor)
+		}
+		onCurve += len(c.OnCurvePoints())
+	}
+
+	// Draw the re-fitted contours on top, along with their knots
+	strokeOp = &vector.StrokeOptions{Width: 3}
+	for _, refit := range g.glyphRefits {
+		for i := 0; i+3 < len(refit); i += 3 {
+			curve, err := bezier.NewBezier(false, refit[i:i+4]...)
+			if err != nil {
+				continue
+			}
+			if g.showComb {
+				drawComb(screen, curve, g.camera)
+			}
+			strokeCurve(screen, g.camera.curve(refit[i:i+4]), strokeOp, curveColor)
+		}
+		for i := 0; i < len(refit); i += 3 {
+			pt := g.camera.toScreen(refit[i])
+			vector.DrawFilledCircle(screen, float32(pt.X), float32(pt.Y), pointDiameter/3, pointColor, true)
+		}
+	}
+
+	textOp := &text.DrawOptions{}
+	textOp.ColorScale.ScaleWithColor(textColor)
+	textOp.GeoM.Translate(float64(padding), float64(padding))
+	status := fmt.Sprintf("Glyph %q: %d contours, %d on-curve points (%s/%s to change, %s to exit)",
+		g.glyphRune, len(g.glyphContours), onCurve,
+		g.keymap.describe(actionPrevGlyph), g.keymap.describe(actionNextGlyph), g.keymap.describe(actionGlyphs))
+	text.Draw(screen, status, text.NewGoXFace(textFont), textOp)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/braheezy/hobby-spline/pkg/scene"
+)
+
+// maxHistory is the number of edits that can be undone.
+const maxHistory = 1000
+
+// command is a reversible edit to the demo's state. Every edit goes through
+// one, so that it can be undone.
+type command interface {
+	do(g *Game)
+	undo(g *Game)
+}
+
+// history holds the edits that can be undone, and those undone that can be
+// redone.
+type history struct {
+	done   []command
+	undone []command
+}
+
+// push records an edit that has already been made. Making it means the
+// edits undone before it can no longer be redone.
+func (h *history) push(c command) {
+	h.done = append(h.done, c)
+	if len(h.done) > maxHistory {
+		h.done = h.done[len(h.done)-maxHistory:]
+	}
+	h.undone = nil
+}
+
+// apply makes an edit and records it.
+func (g *Game) apply(c command) {
+	c.do(g)
+	g.history.push(c)
+}
+
+// undo reverts the last edit, if there is one.
+func (g *Game) undo() {
+	h := &g.history
+	if len(h.done) == 0 {
+		return
+	}
+	c := h.done[len(h.done)-1]
+	h.done = h.done[:len(h.done)-1]
+	c.undo(g)
+	h.undone = append(h.undone, c)
+}
+
+// popHeldEdit takes the edit made by the key being held off the history and
+// returns it, so that the key can amend it rather than make another. It
+// returns false if the key isn't held, something else was edited since, or
+// the edit isn't a C, e.g. because another key was pressed meanwhile.
+func popHeldEdit[C command](g *Game, held bool) (C, bool) {
+	h := &g.history
+	c, ok := g.keyEdit.(C)
+	if !held || !ok || len(h.done) == 0 || h.done[len(h.done)-1] != g.keyEdit {
+		var zero C
+		return zero, false
+	}
+	h.done = h.done[:len(h.done)-1]
+	return c, true
+}
+
+// applyHeld makes an edit with a key, which it can amend while it's held.
+func (g *Game) applyHeld(c command) {
+	g.apply(c)
+	g.keyEdit = c
+}
+
+// redo makes the last undone edit again, if there is one.
+func (g *Game) redo() {
+	h := &g.history
+	if len(h.undone) == 0 {
+		return
+	}
+	c := h.undone[len(h.undone)-1]
+	h.undone = h.undone[:len(h.undone)-1]
+	c.do(g)
+	h.done = append(h.done, c)
+}
+
+// setPoints replaces the knots of a path, e.g. to move, add or remove some.
+type setPoints struct {
+	path     *path
+	from, to []scene.Knot
+	// selection is the knots selected after the edit, and also after
+	// undoing it, as long as they exist then
+	selection []int
+}
+
+func (c *setPoints) do(g *Game) {
+	g.selectPath(c.path)
+	c.path.Points = slices.Clone(c.to)
+	g.selection = c.selection
+}
+
+func (c *setPoints) undo(g *Game) {
+	g.selectPath(c.path)
+	c.path.Points = slices.Clone(c.from)
+	g.selection = c.selection
+	if slices.ContainsFunc(g.selection, func(i int) bool { return i >= len(c.path.Points) }) {
+		g.selection = nil
+	}
+}
+
+// setOmega changes the omega of a path.
+type setOmega struct {
+	path     *path
+	from, to float64
+}
+
+func (c *setOmega) do(g *Game)   { g.selectPath(c.path); c.path.Omega = c.to }
+func (c *setOmega) undo(g *Game) { g.selectPath(c.path); c.path.Omega = c.from }
+
+// setPath changes several things about a path at once, e.g. closing it,
+// which can also remove explicit handles, or changing its algorithm, which
+// can also remove constraints.
+type setPath struct {
+	path     *path
+	from, to scene.Path
+}
+
+func (c *setPath) do(g *Game)   { g.selectPath(c.path); c.path.Path = clonePath(c.to) }
+func (c *setPath) undo(g *Game) { g.selectPath(c.path); c.path.Path = clonePath(c.from) }
+
+// addPath adds a path to the scene.
+type addPath struct {
+	index int
+	path  *path
+}
+
+func (c *addPath) do(g *Game)   { g.insertPath(c.index, c.path) }
+func (c *addPath) undo(g *Game) { g.deletePath(c.index) }
+
+// removePath removes a path from the scene.
+type removePath struct {
+	index int
+	path  *path
+}
+
+func (c *removePath) do(g *Game)   { g.deletePath(c.index) }
+func (c *removePath) undo(g *Game) { g.insertPath(c.index, c.path) }
+
+// setView changes what is shown alongside the curve.
+type setView struct {
+	from, to scene.View
+}
+
+func (c *setView) do(g *Game)   { g.setView(c.to) }
+func (c *setView) undo(g *Game) { g.setView(c.from) }
+
+// replaceScene replaces the whole state, e.g. when a scene is loaded.
+type replaceScene struct {
+	from, to state
+}
+
+func (c *replaceScene) do(g *Game)   { g.setState(c.to) }
+func (c *replaceScene) undo(g *Game) { g.setState(c.from) }
+
+// state is the part of the demo's state that edits change. Its paths are
+// the ones in the scene at the time, rather than copies, so that the edits
+// recorded before and after it still apply to them.
+type state struct {
+	paths   []*path
+	current int
+	view    scene.View
+}
+
+// snapshot returns the current state.
+func (g *Game) snapshot() state {
+	return state{paths: slices.Clone(g.paths), current: g.current, view: g.view()}
+}
+
+// setState replaces the current state with s.
+func (g *Game) setState(s state) {
+	g.paths = slices.Clone(s.paths)
+	g.current = s.current
+	g.selection = nil
+	g.setView(s.view)
+}
+
+// clonePath returns a copy of p that doesn't share its points.
+func clonePath(p scene.Path) scene.Path {
+	p.Points = slices.Clone(p.Points)
+	return p
+}
+
+// view returns the current display settings.
+func (g *Game) view() scene.View {
+	return scene.View{ShowComb: g.showComb, ShowNatural: g.showNatural, ShowHandles: g.showHandles}
+}
+
+func (g *Game) setView(v scene.View) {
+	g.showComb = v.ShowComb
+	g.showNatural = v.ShowNatural
+	g.showHandles = v.ShowHandles
+}
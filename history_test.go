@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/braheezy/hobby-spline/pkg/scene"
+)
+
+// newTestGame returns a game showing the default scene, as main sets it up
+// but without a window.
+func newTestGame() *Game {
+	g := &Game{camera: camera{zoom: 1}, keymap: defaultKeymap()}
+	g.setScene(scene.Default())
+	g.fitSplines()
+	return g
+}
+
+// tick runs the part of a tick that edits the curve, with events as what the
+// pointers did.
+func (g *Game) tick(events ...pointerEvent) {
+	g.pointers.update(events)
+	if !g.updateCamera() {
+		g.updateKnots()
+	}
+	g.fitSplines()
+}
+
+// points returns a copy of the knots of the current path.
+func (g *Game) points() []scene.Knot {
+	return slices.Clone(g.path().Points)
+}
+
+// checkPoints fails the test unless the knots of the current path are want.
+func checkPoints(t *testing.T, g *Game, want []scene.Knot, when string) {
+	t.Helper()
+	if got := g.path().Points; !slices.EqualFunc(got, want, scene.Knot.Equal) {
+		t.Fatalf("%s: knots are %v, want %v", when, got, want)
+	}
+}
+
+func TestHistorySequences(t *testing.T) {
+	g := newTestGame()
+	start := g.points()
+	startOmega := g.path().Omega
+
+	// Move a knot
+	g.selection = []int{1}
+	g.nudge(10, 0, false)
+	moved := g.points()
+	if moved[1].X != start[1].X+10 {
+		t.Fatalf("nudged knot to %v, want it 10 to the right of %v", moved[1], start[1])
+	}
+
+	// Change omega
+	g.stepOmega(omegaStep, false)
+	if g.path().Omega == startOmega {
+		t.Fatal("stepping omega didn't change it")
+	}
+
+	// Remove a knot
+	g.removeKnots([]int{0})
+	removed := g.points()
+	if len(removed) != len(start)-1 {
+		t.Fatalf("removing a knot left %d, want %d", len(removed), len(start)-1)
+	}
+
+	// Close the path
+	path := g.path()
+	to := path.Path
+	to.Closed = true
+	to.Points = pruneHandles(slices.Clone(to.Points), to.Closed)
+	g.apply(&setPath{path: path, from: path.Path, to: to})
+
+	// Undoing goes back through each state in turn
+	g.undo()
+	if g.path().Closed {
+		t.Fatal("undoing closing the path left it closed")
+	}
+	checkPoints(t, g, removed, "undoing closing the path")
+	g.undo()
+	checkPoints(t, g, moved, "undoing removing a knot")
+	g.undo()
+	if g.path().Omega != startOmega {
+		t.Fatalf("undoing the omega change left omega %g, want %g", g.path().Omega, startOmega)
+	}
+	g.undo()
+	checkPoints(t, g, start, "undoing the nudge")
+
+	// Redoing goes forward through them again
+	g.redo()
+	checkPoints(t, g, moved, "redoing the nudge")
+	g.redo()
+	if g.path().Omega == startOmega {
+		t.Fatal("redoing the omega change didn't change omega")
+	}
+	g.redo()
+	checkPoints(t, g, removed, "redoing removing a knot")
+	g.redo()
+	if !g.path().Closed {
+		t.Fatal("redoing closing the path left it open")
+	}
+	if len(g.history.undone) != 0 {
+		t.Fatalf("%d edits left to redo after redoing them all", len(g.history.undone))
+	}
+}
+
+func TestHistoryAddKnot(t *testing.T) {
+	g := newTestGame()
+	start := g.points()
+
+	// Pressing away from the curve appends a knot, which undoing removes
+	// along with its selection
+	g.tick(pointerEvent{kind: pointerPress, id: mousePointer, x: 600, y: 450})
+	g.tick(pointerEvent{kind: pointerRelease, id: mousePointer, x: 600, y: 450})
+	if got := len(g.path().Points); got != len(start)+1 {
+		t.Fatalf("pressing on the canvas left %d knots, want %d", got, len(start)+1)
+	}
+	if !slices.Equal(g.selection, []int{len(start)}) {
+		t.Fatalf("adding a knot selected %v, want it selected", g.selection)
+	}
+	g.undo()
+	checkPoints(t, g, start, "undoing adding a knot")
+	if len(g.selection) != 0 {
+		t.Fatalf("undoing adding a knot left %v selected", g.selection)
+	}
+	g.redo()
+	if got := len(g.path().Points); got != len(start)+1 {
+		t.Fatalf("redoing adding a knot left %d knots, want %d", got, len(start)+1)
+	}
+}
+
+func TestHistoryCoalescesDrags(t *testing.T) {
+	g := newTestGame()
+	start := g.points()
+	k := start[2]
+	x, y := int(k.X), int(k.Y)
+
+	g.tick(pointerEvent{kind: pointerPress, id: mousePointer, x: x, y: y})
+	for i := 1; i <= 10; i++ {
+		g.tick(pointerEvent{kind: pointerMove, id: mousePointer, x: x + 5*i, y: y + 3*i})
+	}
+	g.tick(pointerEvent{kind: pointerRelease, id: mousePointer, x: x + 50, y: y + 30})
+
+	if got := g.path().Points[2]; got.X != k.X+50 || got.Y != k.Y+30 {
+		t.Fatalf("dragged knot to %v, want it at %g, %g", got, k.X+50, k.Y+30)
+	}
+	if len(g.history.done) != 1 {
+		t.Fatalf("dragging over several ticks made %d edits, want 1", len(g.history.done))
+	}
+	g.undo()
+	checkPoints(t, g, start, "undoing the drag")
+}
+
+func TestHistoryCoalescesHeldKeys(t *testing.T) {
+	g := newTestGame()
+	start := g.points()
+
+	g.selection = []int{0}
+	g.nudge(1, 0, false)
+	for i := 0; i < 5; i++ {
+		g.nudge(1, 0, true)
+	}
+	if len(g.history.done) != 1 {
+		t.Fatalf("holding a key made %d edits, want 1", len(g.history.done))
+	}
+
+	// Stepping omega while the nudge key is still held is another edit
+	g.stepOmega(omegaStep, true)
+	g.nudge(1, 0, true)
+	if len(g.history.done) != 3 {
+		t.Fatalf("made %d edits, want 3", len(g.history.done))
+	}
+	g.undo()
+	g.undo()
+	g.undo()
+	checkPoints(t, g, start, "undoing the held keys")
+}
+
+func TestHistoryNewEditClearsRedo(t *testing.T) {
+	g := newTestGame()
+	g.selection = []int{0}
+	g.nudge(10, 0, false)
+	g.nudge(0, 10, false)
+	g.undo()
+	if len(g.history.undone) != 1 {
+		t.Fatalf("%d edits to redo, want 1", len(g.history.undone))
+	}
+
+	g.stepOmega(omegaStep, false)
+	if len(g.history.undone) != 0 {
+		t.Fatalf("%d edits to redo after a new edit, want none", len(g.history.undone))
+	}
+	before := g.points()
+	g.redo()
+	checkPoints(t, g, before, "redoing after a new edit")
+}
+
+func TestHistoryUndoPastBottom(t *testing.T) {
+	g := newTestGame()
+	start := g.points()
+
+	// Nothing to undo or redo yet
+	g.undo()
+	g.redo()
+	checkPoints(t, g, start, "undoing and redoing nothing")
+
+	g.selection = []int{0}
+	g.nudge(10, 0, false)
+	moved := g.points()
+	for i := 0; i < 3; i++ {
+		g.undo()
+	}
+	checkPoints(t, g, start, "undoing past the first edit")
+	if len(g.history.undone) != 1 {
+		t.Fatalf("%d edits to redo, want 1", len(g.history.undone))
+	}
+	g.redo()
+	g.redo()
+	checkPoints(t, g, moved, "redoing past the last edit")
+}
+
+func TestHistoryLimit(t *testing.T) {
+	g := newTestGame()
+	g.selection = []int{0}
+	for i := 0; i < maxHistory+10; i++ {
+		g.nudge(1, 0, false)
+	}
+	if len(g.history.done) != maxHistory {
+		t.Fatalf("kept %d edits, want %d", len(g.history.done), maxHistory)
+	}
+}
+
+func TestSetAlgorithmDropsConstraints(t *testing.T) {
+	direction, curl, tension := 90.0, 2.0, 1.5
+	constrained := []scene.Knot{
+		{X: 100, Y: 200, Curl: &curl, Out: &scene.Handle{X: 150, Y: 150}},
+		{X: 300, Y: 100, Direction: &direction, In: &scene.Handle{X: 250, Y: 100}},
+		{X: 500, Y: 200, Tension: &tension},
+	}
+	unconstrained := []scene.Knot{
+		{X: 100, Y: 200, Out: &scene.Handle{X: 150, Y: 150}},
+		{X: 300, Y: 100, In: &scene.Handle{X: 250, Y: 100}},
+		{X: 500, Y: 200},
+	}
+
+	for _, algorithm := range []string{scene.AlgorithmHobby, scene.AlgorithmNatural} {
+		g := newTestGame()
+		g.path().Algorithm = scene.AlgorithmMetaPost
+		g.path().Points = slices.Clone(constrained)
+
+		g.setAlgorithm(algorithm)
+		checkPoints(t, g, unconstrained, "switching to "+algorithm)
+		data, err := scene.Marshal(g.scene())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := scene.Read(bytes.NewReader(data)); err != nil {
+			t.Fatalf("reading the scene back after switching to %s: %v", algorithm, err)
+		}
+
+		g.undo()
+		checkPoints(t, g, constrained, "undoing the switch to "+algorithm)
+		if g.path().Algorithm != scene.AlgorithmMetaPost {
+			t.Fatalf("undoing the switch to %s left the algorithm %s", algorithm, g.path().Algorithm)
+		}
+		g.redo()
+		checkPoints(t, g, unconstrained, "redoing the switch to "+algorithm)
+
+		// Switching back doesn't bring the constraints back, except by
+		// undoing
+		g.setAlgorithm(scene.AlgorithmMetaPost)
+		checkPoints(t, g, unconstrained, "switching back to metapost")
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/braheezy/hobby-spline/pkg/bezier"
+	"github.com/braheezy/hobby-spline/pkg/scene"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// drawHandles draws the control points of every segment, joined to their
+// knots. Handles that have been dragged, and so are explicit, are drawn in a
+// different color.
+func (g *Game) drawHandles(screen *ebiten.Image) {
+	path := g.path()
+	n := len(path.Points)
+	for i := 0; 3*i+3 < len(path.spline) && n > 0; i++ {
+		clr := handleColor
+		if path.Points[i].Out != nil {
+			clr = explicitColor
+		}
+		start, c0 := g.camera.toScreen(path.spline[3*i]), g.camera.toScreen(path.spline[3*i+1])
+		c1, end := g.camera.toScreen(path.spline[3*i+2]), g.camera.toScreen(path.spline[3*i+3])
+		vector.StrokeLine(screen, float32(start.X), float32(start.Y), float32(c0.X), float32(c0.Y), 1, clr, true)
+		vector.StrokeLine(screen, float32(end.X), float32(end.Y), float32(c1.X), float32(c1.Y), 1, clr, true)
+		vector.DrawFilledCircle(screen, float32(c0.X), float32(c0.Y), handleDiameter/2, clr, true)
+		vector.DrawFilledCircle(screen, float32(c1.X), float32(c1.Y), handleDiameter/2, clr, true)
+	}
+}
+
+// drawInspector shows how the curve passes through the selected knot.
+func (g *Game) drawInspector(screen *ebiten.Image) {
+	path := g.path()
+	// Natural splines are open, even on a path closed with another algorithm
+	closed := path.Closed && path.Algorithm != scene.AlgorithmNatural
+	measures := bezier.MeasureKnots(path.spline, closed)
+	i := g.selectedKnot()
+	if i < 0 || i >= len(path.Points) || i >= len(measures) {
+		return
+	}
+	k, m := path.Points[i], measures[i]
+
+	lines := []string{
+		fmt.Sprintf("Path %d, knot %d of %d", g.current+1, i+1, len(path.Points)),
+		fmt.Sprintf("x %.1f  y %.1f", k.X, k.Y),
+		"alpha " + formatAngle(m.Alpha),
+		"beta  " + formatAngle(m.Beta),
+		"gamma " + formatAngle(m.Gamma),
+		"in  " + formatLength(m.InLength, !math.IsNaN(m.Beta)),
+		"out " + formatLength(m.OutLength, !math.IsNaN(m.Alpha)),
+	}
+	if k.In != nil || k.Out != nil {
+		lines = append(lines, fmt.Sprintf("explicit handles (%s resets)", g.keymap.describe(actionResetHandles)))
+	}
+
+	lineHeight := textFont.Metrics().Height.Ceil()
+	x := screenWidth - inspectorWidth - padding
+	height := len(lines)*lineHeight + padding
+	vector.DrawFilledRect(screen, float32(x), float32(padding), inspectorWidth, float32(height), panelColor, true)
+	vector.StrokeRect(screen, float32(x), float32(padding), inspectorWidth, float32(height), 1, outlineColor, true)
+
+	for i, line := range lines {
+		textOp := &text.DrawOptions{}
+		textOp.ColorScale.ScaleWithColor(textColor)
+		textOp.GeoM.Translate(float64(x+padding/2), float64(padding+padding/2+i*lineHeight))
+		text.Draw(screen, line, text.NewGoXFace(textFont), textOp)
+	}
+}
+
+// formatAngle formats an angle in radians as degrees, or a dash if it's
+// undefined.
+func formatAngle(a float64) string {
+	if math.IsNaN(a) {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f°", a*180/math.Pi)
+}
+
+// formatLength formats a handle length, or a dash if there is no handle.
+func formatLength(l float64, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f", l)
+}
@@ -0,0 +1,449 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"log"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/braheezy/hobby-spline/pkg/scene"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// action is something the keyboard can make the demo do.
+type action int
+
+const (
+	actionHelp action = iota
+	actionSaveScene
+	actionLoadScene
+	actionExportSVG
+	actionUndo
+	actionRedo
+	actionFreehand
+	actionGlyphs
+	actionPrevGlyph
+	actionNextGlyph
+	actionFitView
+	actionShowHandles
+	actionShowComb
+	actionShowNatural
+	actionOmegaDown
+	actionOmegaUp
+	actionNextKnot
+	actionPrevKnot
+	actionNudgeLeft
+	actionNudgeRight
+	actionNudgeUp
+	actionNudgeDown
+	actionNudgeLeftFar
+	actionNudgeRightFar
+	actionNudgeUpFar
+	actionNudgeDownFar
+	actionMoveKnotBack
+	actionMoveKnotForward
+	actionDeleteKnots
+	actionResetHandles
+	actionCancel
+	numActions
+)
+
+// actionInfo describes an action: the name keymap files know it by, what
+// the help overlay says it does, and what it does. Actions that repeat run
+// again and again while their key is held, with held set after the first
+// time.
+type actionInfo struct {
+	name   string
+	help   string
+	repeat bool
+	run    func(g *Game, held bool)
+}
+
+// actions describes every action, indexed by action.
+var actions = [numActions]actionInfo{
+	actionHelp:      {name: "help", help: "Show or hide this help", run: func(g *Game, _ bool) { g.showHelp = !g.showHelp }},
+	actionSaveScene: {name: "saveScene", help: "Save the scene", run: func(g *Game, _ bool) { g.logError("saving scene", g.saveScene()) }},
+	actionLoadScene: {name: "loadScene", help: "Load the scene saved last", run: (*Game).reloadScene},
+	actionExportSVG: {name: "exportSVG", help: "Export the curve as SVG", run: func(g *Game, _ bool) { g.logError("exporting SVG", g.exportSVG()) }},
+	actionUndo: {name: "undo", help: "Undo the last edit", repeat: true, run: func(g *Game, _ bool) {
+		// Edits can't be undone halfway through a drag
+		if !g.dragging() && g.ui.active == "" {
+			g.undo()
+		}
+	}},
+	actionRedo: {name: "redo", help: "Redo the last undone edit", repeat: true, run: func(g *Game, _ bool) {
+		if !g.dragging() && g.ui.active == "" {
+			g.redo()
+		}
+	}},
+	actionFreehand:    {name: "freehand", help: "Enter or leave freehand mode", run: (*Game).toggleFreehand},
+	actionGlyphs:      {name: "glyphs", help: "Enter or leave glyph mode", run: (*Game).toggleGlyphMode},
+	actionPrevGlyph:   {name: "prevGlyph", help: "Show the previous glyph", repeat: true, run: func(g *Game, _ bool) { g.stepGlyphMode(-1) }},
+	actionNextGlyph:   {name: "nextGlyph", help: "Show the next glyph", repeat: true, run: func(g *Game, _ bool) { g.stepGlyphMode(1) }},
+	actionFitView:     {name: "fitView", help: "Fit the view to the curves", run: func(g *Game, _ bool) { g.fitToContent() }},
+	actionShowHandles: {name: "showHandles", help: "Show or hide handles", run: func(g *Game, _ bool) { g.toggleView(func(v *scene.View) { v.ShowHandles = !v.ShowHandles }) }},
+	actionShowComb:    {name: "showComb", help: "Show or hide the comb", run: func(g *Game, _ bool) { g.toggleView(func(v *scene.View) { v.ShowComb = !v.ShowComb }) }},
+	actionShowNatural: {name: "showNatural", help: "Show or hide the natural spline", run: func(g *Game, _ bool) { g.toggleView(func(v *scene.View) { v.ShowNatural = !v.ShowNatural }) }},
+	actionOmegaDown:   {name: "omegaDown", help: "Decrease omega", repeat: true, run: func(g *Game, held bool) { g.stepOmega(-omegaStep, held) }},
+	actionOmegaUp:     {name: "omegaUp", help: "Increase omega", repeat: true, run: func(g *Game, held bool) { g.stepOmega(omegaStep, held) }},
+	actionNextKnot:    {name: "nextKnot", help: "Select the next knot", repeat: true, run: func(g *Game, _ bool) { g.cycleKnots(1) }},
+	actionPrevKnot:    {name: "prevKnot", help: "Select the previous knot", repeat: true, run: func(g *Game, _ bool) { g.cycleKnots(-1) }},
+
+	actionNudgeLeft:     {name: "nudgeLeft", help: "Nudge the selection left", repeat: true, run: func(g *Game, held bool) { g.nudge(-nudgeStep, 0, held) }},
+	actionNudgeRight:    {name: "nudgeRight", help: "Nudge the selection right", repeat: true, run: func(g *Game, held bool) { g.nudge(nudgeStep, 0, held) }},
+	actionNudgeUp:       {name: "nudgeUp", help: "Nudge the selection up", repeat: true, run: func(g *Game, held bool) { g.nudge(0, -nudgeStep, held) }},
+	actionNudgeDown:     {name: "nudgeDown", help: "Nudge the selection down", repeat: true, run: func(g *Game, held bool) { g.nudge(0, nudgeStep, held) }},
+	actionNudgeLeftFar:  {name: "nudgeLeftFar", help: "Nudge the selection further left", repeat: true, run: func(g *Game, held bool) { g.nudge(-farNudgeStep, 0, held) }},
+	actionNudgeRightFar: {name: "nudgeRightFar", help: "Nudge the selection further right", repeat: true, run: func(g *Game, held bool) { g.nudge(farNudgeStep, 0, held) }},
+	actionNudgeUpFar:    {name: "nudgeUpFar", help: "Nudge the selection further up", repeat: true, run: func(g *Game, held bool) { g.nudge(0, -farNudgeStep, held) }},
+	actionNudgeDownFar:  {name: "nudgeDownFar", help: "Nudge the selection further down", repeat: true, run: func(g *Game, held bool) { g.nudge(0, farNudgeStep, held) }},
+
+	actionMoveKnotBack: {name: "moveKnotBack", help: "Move the knot back along the curve", run: func(g *Game, _ bool) {
+		if g.editingKnots() {
+			g.moveKnot(g.selectedKnot(), -1)
+		}
+	}},
+	actionMoveKnotForward: {name: "moveKnotForward", help: "Move the knot forward along the curve", run: func(g *Game, _ bool) {
+		if g.editingKnots() {
+			g.moveKnot(g.selectedKnot(), 1)
+		}
+	}},
+	actionDeleteKnots: {name: "deleteKnots", help: "Delete the selected knots", run: func(g *Game, _ bool) {
+		if g.editingKnots() {
+			g.removeKnots(g.selectedKnots())
+		}
+	}},
+	actionResetHandles: {name: "resetHandles", help: "Reset the handles of the selection", run: func(g *Game, _ bool) {
+		if g.editingKnots() {
+			g.resetHandles(g.selectedKnots())
+		}
+	}},
+	actionCancel: {name: "cancel", help: "Close the help, or clear the selection", run: func(g *Game, _ bool) {
+		if g.showHelp {
+			g.showHelp = false
+		} else if g.editingKnots() {
+			g.selection = nil
+		}
+	}},
+}
+
+// binding is a key, along with whether Ctrl (or Cmd) and Shift must be held
+// with it. They must be released otherwise, so that e.g. S and Ctrl+S can do
+// different things.
+type binding struct {
+	key   ebiten.Key
+	ctrl  bool
+	shift bool
+}
+
+// String returns the binding the way keymap files write it, e.g.
+// "Ctrl+Shift+Z".
+func (b binding) String() string {
+	s := b.key.String()
+	if b.shift {
+		s = "Shift+" + s
+	}
+	if b.ctrl {
+		s = "Ctrl+" + s
+	}
+	return s
+}
+
+// parseBinding parses a binding written as by binding.String. Key names are
+// those of ebiten.Key, and are case-insensitive.
+func parseBinding(s string) (binding, error) {
+	var b binding
+	rest := s
+	for {
+		modifier, key, found := strings.Cut(rest, "+")
+		if !found || key == "" {
+			break
+		}
+		switch strings.ToLower(modifier) {
+		case "ctrl", "cmd":
+			b.ctrl = true
+		case "shift":
+			b.shift = true
+		default:
+			return b, fmt.Errorf("key binding %q: unknown modifier %q", s, modifier)
+		}
+		rest = key
+	}
+	if err := b.key.UnmarshalText([]byte(rest)); err != nil {
+		return b, fmt.Errorf("key binding %q: unknown key %q", s, rest)
+	}
+	return b, nil
+}
+
+// pressed reports whether the binding's key was pressed this tick with the
+// right modifiers held. If repeat is set, it's also reported every
+// keyRepeatInterval ticks once the key has been held for keyRepeatDelay,
+// with held set.
+func (b binding) pressed(repeat bool) (pressed bool, held bool) {
+	ctrl := ebiten.IsKeyPressed(ebiten.KeyControl) || ebiten.IsKeyPressed(ebiten.KeyMeta)
+	if ctrl != b.ctrl || ebiten.IsKeyPressed(ebiten.KeyShift) != b.shift {
+		return false, false
+	}
+	ticks := inpututil.KeyPressDuration(b.key)
+	if ticks == 1 {
+		return true, false
+	}
+	held = repeat && ticks > keyRepeatDelay && (ticks-keyRepeatDelay)%keyRepeatInterval == 0
+	return held, held
+}
+
+// keymap binds actions to the keys that trigger them. An action can have
+// several bindings, and a binding can trigger several actions, as long as
+// no more than one of them applies at a time.
+type keymap map[action][]binding
+
+// defaultKeymap returns the bindings the demo has unless told otherwise.
+func defaultKeymap() keymap {
+	key := func(k ebiten.Key) binding { return binding{key: k} }
+	ctrl := func(k ebiten.Key) binding { return binding{key: k, ctrl: true} }
+	shift := func(k ebiten.Key) binding { return binding{key: k, shift: true} }
+	return keymap{
+		actionHelp:            {shift(ebiten.KeySlash), key(ebiten.KeyF1)},
+		actionSaveScene:       {ctrl(ebiten.KeyS)},
+		actionLoadScene:       {ctrl(ebiten.KeyO)},
+		actionExportSVG:       {key(ebiten.KeyS)},
+		actionUndo:            {ctrl(ebiten.KeyZ)},
+		actionRedo:            {{key: ebiten.KeyZ, ctrl: true, shift: true}, ctrl(ebiten.KeyY)},
+		actionFreehand:        {key(ebiten.KeyF)},
+		actionGlyphs:          {key(ebiten.KeyG)},
+		actionPrevGlyph:       {key(ebiten.KeyArrowLeft)},
+		actionNextGlyph:       {key(ebiten.KeyArrowRight)},
+		actionFitView:         {key(ebiten.KeyHome)},
+		actionShowHandles:     {key(ebiten.KeyH)},
+		actionShowComb:        {key(ebiten.KeyC)},
+		actionShowNatural:     {key(ebiten.KeyN)},
+		actionOmegaDown:       {key(ebiten.KeyBracketLeft)},
+		actionOmegaUp:         {key(ebiten.KeyBracketRight)},
+		actionNextKnot:        {key(ebiten.KeyTab)},
+		actionPrevKnot:        {shift(ebiten.KeyTab)},
+		actionNudgeLeft:       {key(ebiten.KeyArrowLeft)},
+		actionNudgeRight:      {key(ebiten.KeyArrowRight)},
+		actionNudgeUp:         {key(ebiten.KeyArrowUp)},
+		actionNudgeDown:       {key(ebiten.KeyArrowDown)},
+		actionNudgeLeftFar:    {shift(ebiten.KeyArrowLeft)},
+		actionNudgeRightFar:   {shift(ebiten.KeyArrowRight)},
+		actionNudgeUpFar:      {shift(ebiten.KeyArrowUp)},
+		actionNudgeDownFar:    {shift(ebiten.KeyArrowDown)},
+		actionMoveKnotBack:    {key(ebiten.KeyComma)},
+		actionMoveKnotForward: {key(ebiten.KeyPeriod)},
+		actionDeleteKnots:     {key(ebiten.KeyDelete), key(ebiten.KeyBackspace)},
+		actionResetHandles:    {key(ebiten.KeyR)},
+		actionCancel:          {key(ebiten.KeyEscape)},
+	}
+}
+
+// load changes the bindings of the actions in a keymap file, which is a JSON
+// object from action names to lists of bindings, for example:
+//
+//	{"undo": ["Ctrl+Z", "Ctrl+U"], "exportSVG": []}
+//
+// Actions missing from the file keep their bindings, and those given an
+// empty list lose them.
+func (km keymap) load(data []byte) error {
+	var doc map[string][]string
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("keymap: %w", err)
+	}
+	for name, keys := range doc {
+		a := slices.IndexFunc(actions[:], func(info actionInfo) bool { return info.name == name })
+		if a < 0 {
+			return fmt.Errorf("keymap: unknown action %q", name)
+		}
+		bindings := []binding{}
+		for _, s := range keys {
+			b, err := parseBinding(s)
+			if err != nil {
+				return fmt.Errorf("keymap: %w", err)
+			}
+			bindings = append(bindings, b)
+		}
+		km[action(a)] = bindings
+	}
+	return nil
+}
+
+// describe returns the bindings of an action, for showing to the user.
+func (km keymap) describe(a action) string {
+	if len(km[a]) == 0 {
+		return "unbound"
+	}
+	names := make([]string, len(km[a]))
+	for i, b := range km[a] {
+		names[i] = b.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// updateKeys runs the actions whose keys were pressed this tick, or are
+// being held for those that repeat.
+func (g *Game) updateKeys() {
+	for a := action(0); a < numActions; a++ {
+		info := actions[a]
+		for _, b := range g.keymap[a] {
+			if pressed, held := b.pressed(info.repeat); pressed {
+				info.run(g, held)
+				break
+			}
+		}
+	}
+}
+
+// editingKnots reports whether the knots of the paths are on screen to be
+// edited, and aren't being dragged, so that keys can edit them.
+func (g *Game) editingKnots() bool {
+	return !g.freehand && !g.glyphMode && !g.dragging()
+}
+
+// logError logs err, if it isn't nil, as having happened while doing what.
+func (g *Game) logError(what string, err error) {
+	if err != nil {
+		log.Printf("%s: %v", what, err)
+	}
+}
+
+// reloadScene replaces the scene with the one in the scene file, as a single
+// edit.
+func (g *Game) reloadScene(bool) {
+	g.endDrag()
+	before := g.snapshot()
+	if err := g.loadScene(); err != nil {
+		log.Printf("loading scene: %v", err)
+		return
+	}
+	g.history.push(&replaceScene{from: before, to: g.snapshot()})
+}
+
+// toggleFreehand enters freehand mode, or leaves it.
+func (g *Game) toggleFreehand(bool) {
+	g.endDrag()
+	g.freehand = !g.freehand
+	g.glyphMode = false
+	g.drawingStroke = false
+}
+
+// toggleGlyphMode enters glyph mode, or leaves it.
+func (g *Game) toggleGlyphMode(bool) {
+	g.endDrag()
+	g.glyphMode = !g.glyphMode
+	g.freehand = false
+	if g.glyphMode {
+		if err := g.loadGlyph(g.glyphRune); err != nil {
+			log.Printf("loading glyph: %v", err)
+			g.glyphMode = false
+		}
+	}
+}
+
+// stepGlyphMode steps through the glyphs, if they're being shown.
+func (g *Game) stepGlyphMode(step rune) {
+	if g.glyphMode {
+		g.stepGlyph(step)
+	}
+}
+
+// toggleView changes the display settings with change, as an edit.
+func (g *Game) toggleView(change func(v *scene.View)) {
+	if g.dragging() {
+		return
+	}
+	view := g.view()
+	change(&view)
+	g.apply(&setView{from: g.view(), to: view})
+}
+
+// stepOmega changes the omega of the current path by step, keeping it a
+// multiple of the step between 0 and 1. While the key is held, the changes
+// make up a single edit.
+func (g *Game) stepOmega(step float64, held bool) {
+	if g.ui.active != "" {
+		return
+	}
+	path := g.path()
+	omega := math.Max(0, math.Min(1, math.Round((path.Omega+step)/omegaStep)/(1/omegaStep)))
+	if omega == path.Omega {
+		return
+	}
+	c := &setOmega{path: path, from: path.Omega, to: omega}
+	if prev, ok := popHeldEdit[*setOmega](g, held); ok {
+		c.from = prev.from
+	}
+	g.applyHeld(c)
+}
+
+// cycleKnots selects the knot offset places along the current path from the
+// selected one, or the one at the start or end of the path if none is
+// selected.
+func (g *Game) cycleKnots(offset int) {
+	if !g.editingKnots() {
+		return
+	}
+	n := len(g.path().Points)
+	switch {
+	case len(g.selection) == 0 && offset > 0:
+		g.selection = []int{0}
+	case len(g.selection) == 0:
+		g.selection = []int{n - 1}
+	case offset > 0:
+		g.selection = []int{(g.selection[len(g.selection)-1] + offset) % n}
+	default:
+		g.selection = []int{((g.selection[0]+offset)%n + n) % n}
+	}
+}
+
+// nudge moves the selected knots by dx, dy pixels on screen. While the key
+// is held, the moves make up a single edit.
+func (g *Game) nudge(dx, dy float64, held bool) {
+	selection := g.selectedKnots()
+	if !g.editingKnots() || len(selection) == 0 {
+		return
+	}
+	path := g.path()
+	m := translation(dx/g.camera.zoom, dy/g.camera.zoom)
+	points := slices.Clone(path.Points)
+	for _, i := range selection {
+		points[i] = m.knot(points[i])
+	}
+	c := &setPoints{path: path, from: path.Points, to: points, selection: selection}
+	if prev, ok := popHeldEdit[*setPoints](g, held); ok {
+		c.from = prev.from
+	}
+	g.applyHeld(c)
+}
+
+// drawHelp draws the help overlay, listing what every key does.
+func (g *Game) drawHelp(screen *ebiten.Image) {
+	if !g.showHelp {
+		return
+	}
+	var keys, helps []string
+	keysWidth, helpWidth := 0, 0
+	for a := action(0); a < numActions; a++ {
+		keys = append(keys, g.keymap.describe(a))
+		helps = append(helps, actions[a].help)
+		keysWidth = max(keysWidth, textWidth(keys[a], textFont))
+		helpWidth = max(helpWidth, textWidth(helps[a], textFont))
+	}
+
+	// The actions are listed in two columns
+	lineHeight := textFont.Metrics().Height.Ceil()
+	rows := (len(keys) + 1) / 2
+	columnWidth := keysWidth + widgetGap + helpWidth
+	width, height := 2*columnWidth+3*padding, rows*lineHeight+2*padding
+	r := image.Rect(0, 0, width, height).Add(image.Pt((screenWidth-width)/2, (screenHeight-toolbarHeight-height)/2))
+	drawBox(screen, r, panelColor, widgetIdle)
+	for i := range keys {
+		x := r.Min.X + padding + (i/rows)*(columnWidth+padding)
+		y := r.Min.Y + padding + (i%rows)*lineHeight + lineHeight/2
+		drawText(screen, keys[i], x, y)
+		drawText(screen, helps[i], x+keysWidth+widgetGap, y)
+	}
+}
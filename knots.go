@@ -0,0 +1,387 @@
+package main
+
+import (
+	"math"
+	"slices"
+
+	"github.com/braheezy/hobby-spline/pkg/bezier"
+	"github.com/braheezy/hobby-spline/pkg/scene"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// drag is a knot, or one of its handles, being dragged by a pointer.
+type drag struct {
+	knot   int
+	handle handleSide
+	// added is set when the knot was added by the press starting the drag
+	added bool
+	// snapTo are the segments of the curve the knot can snap to
+	snapTo []*bezier.Bezier
+	// offsetX and offsetY are from what's dragged to the pointer, in world
+	// coordinates
+	offsetX, offsetY float64
+	// tool is set when the drag selects knots or transforms the selected
+	// ones, rather than dragging a single knot or handle
+	tool *toolDrag
+}
+
+// handleSide says which of a knot's handles, if either, is meant.
+type handleSide int
+
+const (
+	noHandle handleSide = iota
+	inHandle
+	outHandle
+)
+
+// updateKnots drags, adds, deletes and reorders knots, drags handles, and
+// selects and transforms groups of knots.
+//
+// Pressing on a knot drags it. Pressing on the curve inserts a knot there,
+// and pressing anywhere else on the canvas appends one to the end of the
+// curve; either way the new knot can be dragged straight away. Pressing on a
+// knot or the curve of another path selects that path first. Right-clicking
+// a knot, or holding a touch still on it, deletes it. Each finger on a touch
+// screen drags a knot of its own.
+//
+// When handles are shown, dragging one overrides the control points of its
+// segment with explicit ones. See startTool for selecting and transforming
+// several knots at once.
+func (g *Game) updateKnots() {
+	g.guides = g.guides[:0]
+	for _, p := range g.pointers.released {
+		if d, ok := g.drags[p.id]; ok {
+			g.moveDrag(d, p)
+			delete(g.drags, p.id)
+			if len(g.drags) == 0 {
+				g.recordDrag()
+			}
+		}
+	}
+	for _, p := range g.pointers.down {
+		d, ok := g.drags[p.id]
+		if !ok {
+			continue
+		}
+		if p.longPressed() && d.handle == noHandle && d.tool == nil && len(g.drags) == 1 {
+			g.cancelDrag()
+			if !d.added {
+				g.removeKnots([]int{d.knot})
+			}
+			return
+		}
+		g.moveDrag(d, p)
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) && !g.dragging() {
+		x, y := g.pointers.cursorX, g.pointers.cursorY
+		if i := g.pathAt(x, y); i >= 0 {
+			g.selectPath(g.paths[i])
+			if i := g.path().knotAt(g.camera, x, y); i >= 0 {
+				g.removeKnots([]int{i})
+			}
+		}
+	}
+	for _, p := range g.pointers.down {
+		if p.justPressed && !g.ui.capturing(p.x, p.y) {
+			g.startDrag(p)
+		}
+	}
+}
+
+// startDrag starts dragging whatever p was pressed on, adding a knot there
+// if it was pressed on nothing.
+func (g *Game) startDrag(p *pointer) {
+	// Selecting and transforming groups of knots take a single pointer
+	for _, d := range g.drags {
+		if d.tool != nil {
+			return
+		}
+	}
+
+	// Whatever is dragged, the drags are recorded as a single edit when the
+	// last one ends, including adding knots in the first place. Until then,
+	// the path being edited stays selected.
+	if !g.dragging() {
+		if i := g.pathAt(p.x, p.y); i >= 0 {
+			g.selectPath(g.paths[i])
+		}
+		g.dragStart = slices.Clone(g.path().Points)
+		g.selectionStart = g.selection
+	}
+	if g.drags == nil {
+		g.drags = make(map[pointerID]*drag)
+	}
+	path := g.path()
+	cursor := g.camera.toWorld(float64(p.x), float64(p.y))
+
+	if g.showHandles {
+		if segment, in, ok := path.handleAt(g.camera, p.x, p.y); ok {
+			g.drags[p.id] = g.startHandleDrag(segment, in, cursor)
+			return
+		}
+	}
+	if len(g.drags) == 0 {
+		if t := g.startTool(p); t != nil {
+			g.drags[p.id] = &drag{knot: -1, tool: t}
+			return
+		}
+	}
+
+	d := &drag{knot: path.knotAt(g.camera, p.x, p.y)}
+	if d.knot >= 0 {
+		d.snapTo = g.snapCurves(d.knot, false)
+	} else {
+		at := cursor
+		if segment, onCurve, ok := path.curveAt(g.camera, cursor); ok {
+			d.knot = segment + 1
+			at = onCurve
+			d.snapTo = g.snapCurves(segment, true)
+		} else {
+			d.knot = len(path.Points)
+			d.snapTo = g.snapCurves(-1, true)
+		}
+		path.Points = pruneHandles(slices.Insert(path.Points, d.knot, scene.Knot{X: at.X, Y: at.Y}), path.Closed)
+		d.added = true
+		// Knots being dragged by other pointers may have moved along
+		for _, other := range g.drags {
+			if other.knot >= d.knot {
+				other.knot++
+			}
+		}
+	}
+
+	g.selection = []int{d.knot}
+	d.offsetX = cursor.X - path.Points[d.knot].X
+	d.offsetY = cursor.Y - path.Points[d.knot].Y
+	g.drags[p.id] = d
+}
+
+// moveDrag moves what d is dragging to follow p.
+func (g *Game) moveDrag(d *drag, p *pointer) {
+	if d.tool != nil {
+		g.moveTool(d.tool, p)
+		return
+	}
+	cursor := g.camera.toWorld(float64(p.x), float64(p.y))
+	to := bezier.Point{X: cursor.X - d.offsetX, Y: cursor.Y - d.offsetY}
+	k := &g.path().Points[d.knot]
+	switch d.handle {
+	case inHandle:
+		k.In = &scene.Handle{X: to.X, Y: to.Y}
+	case outHandle:
+		k.Out = &scene.Handle{X: to.X, Y: to.Y}
+	default:
+		if g.snapping() {
+			to = g.snapKnot(d.knot, d, to)
+		}
+		g.moveDraggedKnot(d.knot, to.X, to.Y)
+	}
+}
+
+// dragging reports whether any knot or handle is being dragged.
+func (g *Game) dragging() bool {
+	return len(g.drags) != 0
+}
+
+// draggingExisting reports whether knots or handles are being dragged, none
+// of them added by the drag.
+func (g *Game) draggingExisting() bool {
+	for _, d := range g.drags {
+		if d.added {
+			return false
+		}
+	}
+	return g.dragging()
+}
+
+// moveDraggedKnot moves the knot at index i to x, y, taking its handles along
+// with it.
+func (g *Game) moveDraggedKnot(i int, x, y float64) {
+	k := &g.path().Points[i]
+	dx, dy := x-k.X, y-k.Y
+	if dx == 0 && dy == 0 {
+		return
+	}
+	k.X, k.Y = x, y
+	if k.In != nil {
+		k.In = &scene.Handle{X: k.In.X + dx, Y: k.In.Y + dy}
+	}
+	if k.Out != nil {
+		k.Out = &scene.Handle{X: k.Out.X + dx, Y: k.Out.Y + dy}
+	}
+}
+
+// startHandleDrag starts dragging a handle of a segment: the one arriving at
+// its end if in is set, otherwise the one leaving its start. Both handles of
+// the segment become explicit, where the curve currently has them. cursor is
+// where the drag started, in world coordinates.
+func (g *Game) startHandleDrag(segment int, in bool, cursor bezier.Point) *drag {
+	path := g.path()
+	from, to := segment, (segment+1)%len(path.Points)
+	out := path.spline[3*segment+1]
+	path.Points[from].Out = &scene.Handle{X: out.X, Y: out.Y}
+	arrive := path.spline[3*segment+2]
+	path.Points[to].In = &scene.Handle{X: arrive.X, Y: arrive.Y}
+
+	d, h := &drag{knot: from, handle: outHandle}, path.Points[from].Out
+	if in {
+		d, h = &drag{knot: to, handle: inHandle}, path.Points[to].In
+	}
+	g.selection = []int{d.knot}
+	d.offsetX, d.offsetY = cursor.X-h.X, cursor.Y-h.Y
+	return d
+}
+
+// endDrag finishes dragging every knot and handle, recording the drags as a
+// single edit.
+func (g *Game) endDrag() {
+	if !g.dragging() {
+		return
+	}
+	clear(g.drags)
+	g.guides = g.guides[:0]
+	g.recordDrag()
+}
+
+// recordDrag records the drags that have just finished as a single edit.
+func (g *Game) recordDrag() {
+	path := g.path()
+	if !slices.EqualFunc(g.dragStart, path.Points, scene.Knot.Equal) {
+		g.history.push(&setPoints{path: path, from: g.dragStart, to: slices.Clone(path.Points), selection: g.selection})
+	}
+}
+
+// cancelDrag puts everything back the way it was before the drags.
+func (g *Game) cancelDrag() {
+	if !g.dragging() {
+		return
+	}
+	clear(g.drags)
+	g.guides = g.guides[:0]
+	g.path().Points = g.dragStart
+	g.selection = g.selectionStart
+}
+
+// knotAt returns the index of the knot of the path at screen position x, y
+// as seen through cam, or -1 if there is none.
+func (p *path) knotAt(cam camera, x, y int) int {
+	for i, k := range p.Points {
+		s := cam.toScreen(bezier.Point{X: k.X, Y: k.Y})
+		if math.Hypot(float64(x)-s.X, float64(y)-s.Y) <= knotGrabRadius {
+			return i
+		}
+	}
+	return -1
+}
+
+// handleAt finds the handle of the path at screen position x, y as seen
+// through cam, returning the index of its segment and whether it's the
+// handle arriving at the end of the segment.
+func (p *path) handleAt(cam camera, x, y int) (int, bool, bool) {
+	for i := 0; 3*i+2 < len(p.spline); i++ {
+		for side, handle := range p.spline[3*i+1 : 3*i+3] {
+			s := cam.toScreen(handle)
+			if math.Hypot(float64(x)-s.X, float64(y)-s.Y) <= handleGrabRadius {
+				return i, side == 1, true
+			}
+		}
+	}
+	return 0, false, false
+}
+
+// curveAt finds the segment of the path that passes near at, returning its
+// index and the point on it closest to at. at is in world coordinates, but
+// "near" is measured on screen as seen through cam.
+func (p *path) curveAt(cam camera, at bezier.Point) (int, bezier.Point, bool) {
+	curves, err := bezier.Segments(p.spline)
+	if err != nil {
+		return 0, bezier.Point{}, false
+	}
+	best, bestDistance := -1, float64(curveGrabDistance)
+	var bestPoint bezier.Point
+	for i, curve := range curves {
+		onCurve, _ := curve.Project(at)
+		if d := math.Hypot(onCurve.X-at.X, onCurve.Y-at.Y) * cam.zoom; d <= bestDistance {
+			best, bestDistance, bestPoint = i, d, onCurve
+		}
+	}
+	return best, bestPoint, best >= 0
+}
+
+// removeKnots removes the knots at the given indices, unless that would
+// leave too few to draw a curve through.
+func (g *Game) removeKnots(indices []int) {
+	path := g.path()
+	indices = existingKnots(indices, len(path.Points))
+	if len(indices) == 0 || len(path.Points)-len(indices) < minKnots {
+		return
+	}
+	var points []scene.Knot
+	for i, k := range path.Points {
+		if !slices.Contains(indices, i) {
+			points = append(points, k)
+		}
+	}
+	g.apply(&setPoints{path: path, from: path.Points, to: pruneHandles(points, path.Closed)})
+}
+
+// moveKnot moves the knot at index i by offset places along the curve.
+func (g *Game) moveKnot(i int, offset int) {
+	path := g.path()
+	j := i + offset
+	if i < 0 || i >= len(path.Points) || j < 0 || j >= len(path.Points) {
+		return
+	}
+	points := slices.Clone(path.Points)
+	points[i], points[j] = points[j], points[i]
+	g.apply(&setPoints{path: path, from: path.Points, to: pruneHandles(points, path.Closed), selection: []int{j}})
+}
+
+// resetHandles removes the explicit handles either side of the knots at the
+// given indices, leaving the algorithm to choose them again.
+func (g *Game) resetHandles(indices []int) {
+	path := g.path()
+	points := slices.Clone(path.Points)
+	indices = existingKnots(indices, len(points))
+	for _, i := range indices {
+		points[i].In, points[i].Out = nil, nil
+	}
+	points = pruneHandles(points, path.Closed)
+	if !slices.EqualFunc(points, path.Points, scene.Knot.Equal) {
+		g.apply(&setPoints{path: path, from: path.Points, to: points, selection: indices})
+	}
+}
+
+// pruneHandles removes explicit handles that have lost their partner at the
+// other end of their segment, e.g. because a knot was inserted in between.
+func pruneHandles(points []scene.Knot, closed bool) []scene.Knot {
+	n := len(points)
+	if n == 0 {
+		return points
+	}
+	if !closed {
+		points[0].In, points[n-1].Out = nil, nil
+	}
+	for i := range points {
+		next := (i + 1) % n
+		if !closed && next == 0 {
+			break
+		}
+		if (points[i].Out == nil) != (points[next].In == nil) {
+			points[i].Out, points[next].In = nil, nil
+		}
+	}
+	return points
+}
+
+// pruneConstraints removes the directions, curls and tensions of knots, which
+// only the MetaPost algorithm honours. Explicit handles are kept, since every
+// algorithm honours those.
+func pruneConstraints(points []scene.Knot) []scene.Knot {
+	for i := range points {
+		points[i].Direction, points[i].Curl, points[i].Tension = nil, nil, nil
+	}
+	return points
+}
@@ -1,215 +1,511 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"flag"
 	"fmt"
 	"image/color"
+	"io/fs"
 	"log"
-	"math"
+	"os"
 
 	"github.com/braheezy/hobby-spline/pkg/bezier"
+	"github.com/braheezy/hobby-spline/pkg/scene"
 	"golang.org/x/image/font"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
 func main() {
+	sceneFile := flag.String("scene", sceneFileName, "scene file to start with, and to save to and load from with Ctrl+S and Ctrl+O")
+	pathData := flag.String("path", "", "SVG path data whose on-curve points are loaded as knots")
+	omega := flag.Float64("omega", scene.DefaultPath().Omega, "curl at the end points of the Hobby splines, between 0 and 1")
+	epsFile := flag.String("eps", "", "write the curve to this Encapsulated PostScript file and exit")
+	pdfFile := flag.String("pdf", "", "write the curve to this PDF file and exit")
+	pngFile := flag.String("png", "", "render the curve and its overlays to this PNG file and exit")
+	grid := flag.Float64("grid", defaultGridSpacing, "spacing of the grid dragged knots snap to, or 0 for no grid")
+	keymapFile := flag.String("keymap", "", "JSON file changing which keys do what")
+	flag.Parse()
+
 	ebiten.SetWindowSize(screenWidth, screenHeight)
 	ebiten.SetWindowTitle("Hobby's algorithm for aesthetic Bézier splines")
-	game := &Game{
-		points: []bezier.Point{
-			{X: 356, Y: 229},
-			{X: 523, Y: 287},
-			{X: 505, Y: 72},
-			{X: 109, Y: 224},
-			{X: 108, Y: 92},
-			{X: 232, Y: 307},
-		},
-		omega:       0.75,
-		showComb:    true,
-		showNatural: true,
+	game := &Game{sceneFile: *sceneFile, glyphRune: firstGlyph, camera: camera{zoom: 1}, gridSpacing: *grid, keymap: defaultKeymap()}
+	if *keymapFile != "" {
+		data, err := os.ReadFile(*keymapFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := game.keymap.load(data); err != nil {
+			log.Fatal(err)
+		}
+	}
+	game.setScene(scene.Default())
+
+	// Start where the last session saved off, if it did. A scene that
+	// can't be read is left for the user to fix, starting from the default
+	// one instead.
+	if err := game.loadScene(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("loading scene: %v", err)
+	}
+
+	// Flags given explicitly override the scene
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "omega" {
+			for _, p := range game.paths {
+				p.Omega = *omega
+			}
+		}
+	})
+
+	// In the browser, the path is passed as a query parameter instead
+	if *pathData == "" {
+		*pathData = queryParam("path")
+	}
+	if *pathData != "" {
+		points, err := loadSVGPath(*pathData)
+		if err != nil {
+			log.Fatal(err)
+		}
+		game.path().Points = scene.Knots(points)
+	}
+
+	// File output doesn't need a window
+	if *epsFile != "" || *pdfFile != "" || *pngFile != "" {
+		if err := writeOutputFiles(game, *epsFile, *pdfFile, *pngFile); err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
+
 	if err := ebiten.RunGame(game); err != nil {
 		log.Fatal(err)
 	}
 }
 
 type Game struct {
-	points         []bezier.Point
-	splinePoints   []bezier.Point
-	omega          float64
-	showComb       bool
-	showNatural    bool
-	sliderDragging bool
-	draggingPoint  *bezier.Point
-	dragOffsetX    float32
-	dragOffsetY    float32
+	// paths are the curves in the scene, and current is the index of the
+	// one being edited, which the toolbar and keys act on
+	paths       []*path
+	current     int
+	showComb    bool
+	showNatural bool
+	showHandles bool
+	// drags are the knots and handles being dragged, by the pointer
+	// dragging them. Handles are never changed in place, since knots
+	// copied into the history share them.
+	drags map[pointerID]*drag
+	// selection is the indices of the selected knots of the current path,
+	// in order, which keys like Delete and the arrows act on.
+	selection []int
+	// The mouse and every finger on the touch screen
+	pointers pointers
+	// Whether dragged knots snap, the spacing of the grid they snap to, and
+	// what they snapped to this tick
+	snap        bool
+	gridSpacing float64
+	guides      []guide
+
+	// Freehand mode: the user draws a stroke, which is then fitted
+	freehand      bool
+	drawingStroke bool
+	stroke        []bezier.Point
+	fittedPoints  []bezier.Point
+
+	// Glyph mode: the outline of a glyph is compared with Hobby splines
+	// through its on-curve points
+	glyphMode     bool
+	glyphRune     rune
+	glyphContours []bezier.Contour
+	glyphRefits   [][]bezier.Point
+
+	// Where Ctrl+S and Ctrl+O save and load the scene
+	sceneFile string
+
+	// The view onto the curve, and the screen position the current pan
+	// last moved it from
+	camera  camera
+	panning bool
+	panX    int
+	panY    int
+
+	// Edits that can be undone and redone. Drags are recorded as a single
+	// edit when they end, from the state they started in, and cancelling
+	// them puts back the knots and selection they started with.
+	history        history
+	dragStart      []scene.Knot
+	selectionStart []int
+	omegaStart     float64
+	// keyEdit is the edit made by the key last pressed, which it amends
+	// rather than making new ones while it's held
+	keyEdit command
+
+	// The keys bound to each action, and whether the help listing them is
+	// shown
+	keymap   keymap
+	showHelp bool
+
+	ui ui
 }
 
 func (g *Game) Update() error {
-
-	updateOmega := func(x int) {
-		g.omega = float64(x-sliderX) / float64(sliderWidth)
-		if g.omega < 0 {
-			g.omega = 0
-		} else if g.omega > 1 {
-			g.omega = 1
-		}
+	g.pointers.update(g.pointers.poll())
+
+	// The toolbar follows the first pointer down
+	x, y := g.pointers.cursorX, g.pointers.cursorY
+	var down, justPressed bool
+	if p := g.pointers.primary(); p != nil {
+		x, y = p.x, p.y
+		down, justPressed = !p.justReleased, p.justPressed
 	}
+	g.ui.begin(x, y, down, justPressed)
+	g.updateToolbar()
 
-	// Collect current touch states
-	touchIDs := ebiten.AppendTouchIDs(nil)
-	var x, y int
-	var inputJustPressed bool
-	var inputJustReleased bool
+	g.updateKeys()
 
-	if len(touchIDs) > 0 {
-		// Use touch input
-		x, y = ebiten.TouchPosition(touchIDs[0])
-		inputJustPressed = len(inpututil.AppendJustPressedTouchIDs(touchIDs)) > 0
-		inputJustReleased = inpututil.IsTouchJustReleased(touchIDs[0])
-	} else {
-		// Use mouse input
-		x, y = ebiten.CursorPosition()
-		inputJustPressed = inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft)
-		inputJustReleased = inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft)
-	}
-
-	// Check mouse interactions with slider knob
-	if g.sliderDragging {
-		if inputJustReleased {
-			g.sliderDragging = false
-		} else {
-			updateOmega(x)
-		}
-	} else if inputJustPressed {
-		knobX := sliderX + int(g.omega*float64(sliderWidth))
-		// Check if mouse is within slider knob
-		if x >= knobX-sliderKnobDiameter/2 && x <= knobX+sliderKnobDiameter/2 && y >= sliderY && y <= sliderY+sliderHeight {
-			g.sliderDragging = true
-		} else if x >= combToggleX-toggleRadius && x <= combToggleX+toggleRadius && y >= combToggleY-toggleRadius && y <= combToggleY-toggleRadius+toggleDiameter {
-			// Check if mouse is within comb toggle
-			g.showComb = !g.showComb
-		}
-		// Check if mouse is within natural toggle
-		if x >= naturalToggleX-toggleRadius && x <= naturalToggleX+toggleRadius && y >= naturalToggleY-toggleRadius && y <= naturalToggleY-toggleRadius+toggleDiameter {
-			g.showNatural = !g.showNatural
-		}
+	// Panning and zooming take priority over editing
+	panned := g.updateCamera()
+
+	if g.glyphMode {
+		return nil
 	}
 
-	// Handle point dragging logic for bezier curves
-	if g.draggingPoint != nil {
-		if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) {
-			g.draggingPoint = nil
-		} else {
-			x, y := ebiten.CursorPosition()
-			g.draggingPoint.X = float64(x) - float64(g.dragOffsetX)
-			g.draggingPoint.Y = float64(y) - float64(g.dragOffsetY)
-		}
-	} else if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-		x, y := ebiten.CursorPosition()
-		for i := range g.points {
-			px, py := g.points[i].X, g.points[i].Y
-			if (x-int(px))*(x-int(px))+(y-int(py))*(y-int(py)) <= 25*25 { // 25 pixels radius for easier clicking
-				g.draggingPoint = &g.points[i]
-				g.dragOffsetX = float32(x) - float32(px)
-				g.dragOffsetY = float32(y) - float32(py)
-				break
-			}
+	if g.freehand {
+		if !panned {
+			g.updateFreehand(g.pointers.primary())
 		}
+		return nil
+	}
+
+	if !panned {
+		g.updateKnots()
 	}
 
-	g.splinePoints, _ = bezier.CreateHobbySpline(g.points, g.omega)
+	g.fitSplines()
 
 	return nil
 }
 
+// fitSplines fits the spline of every path to its knots.
+func (g *Game) fitSplines() {
+	for _, p := range g.paths {
+		p.spline, _ = p.Spline()
+	}
+}
+
+// updateFreehand records a stroke while the pointer is held down and fits
+// Bézier curves to it once released. The stroke is recorded in world
+// coordinates, and fitted to within fitTolerance pixels on screen.
+func (g *Game) updateFreehand(ptr *pointer) {
+	if ptr == nil {
+		return
+	}
+	p := g.camera.toWorld(float64(ptr.x), float64(ptr.y))
+	if g.drawingStroke {
+		if p != g.stroke[len(g.stroke)-1] {
+			g.stroke = append(g.stroke, p)
+		}
+		if ptr.justReleased {
+			g.drawingStroke = false
+			g.fittedPoints, _ = bezier.FitCurve(g.stroke, fitTolerance/g.camera.zoom, fitCornerAngle)
+		}
+	} else if ptr.justPressed && !g.ui.capturing(ptr.x, ptr.y) {
+		g.drawingStroke = true
+		g.stroke = []bezier.Point{p}
+		g.fittedPoints = nil
+	}
+}
+
 func (g *Game) Draw(screen *ebiten.Image) {
 	// Set background
 	screen.Fill(backgroundColor)
 
-	strokeOp := &vector.StrokeOptions{Width: 1}
+	if g.freehand {
+		g.drawFreehand(screen)
+	} else if g.glyphMode {
+		g.drawGlyph(screen)
+	} else {
+		g.drawHobby(screen)
+		g.drawInspector(screen)
+	}
+
+	g.ui.draw(screen)
+	g.drawHelp(screen)
+}
+
+func (g *Game) drawFreehand(screen *ebiten.Image) {
+	// Draw the raw stroke
+	for i := 1; i < len(g.stroke); i++ {
+		p0, p1 := g.camera.toScreen(g.stroke[i-1]), g.camera.toScreen(g.stroke[i])
+		vector.StrokeLine(screen, float32(p0.X), float32(p0.Y), float32(p1.X), float32(p1.Y), 1, naturalCurveColor, true)
+	}
+
+	// Draw the fitted curves on top, along with their knots
+	strokeOp := &vector.StrokeOptions{Width: 3}
+	for i := 0; i+3 < len(g.fittedPoints); i += 3 {
+		pts := g.fittedPoints[i : i+4]
+		curve, err := bezier.NewBezier(false, pts...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if g.showComb {
+			drawComb(screen, curve, g.camera)
+		}
+		strokeCurve(screen, g.camera.curve(pts), strokeOp, curveColor)
+	}
+	for i := 0; i < len(g.fittedPoints); i += 3 {
+		pt := g.camera.toScreen(g.fittedPoints[i])
+		vector.DrawFilledCircle(screen, float32(pt.X), float32(pt.Y), pointDiameter/3, pointColor, true)
+	}
+
+	textOp := &text.DrawOptions{}
+	textOp.ColorScale.ScaleWithColor(textColor)
+	textOp.GeoM.Translate(float64(padding), float64(padding))
+	status := fmt.Sprintf("Freehand: draw a stroke (%s to exit)", g.keymap.describe(actionFreehand))
+	if len(g.fittedPoints) != 0 {
+		status = fmt.Sprintf("Freehand: %d samples fitted with %d curves (%s to exit)", len(g.stroke), (len(g.fittedPoints)-1)/3, g.keymap.describe(actionFreehand))
+	}
+	text.Draw(screen, status, text.NewGoXFace(textFont), textOp)
+}
+
+func (g *Game) drawHobby(screen *ebiten.Image) {
+	if g.snap && g.gridSpacing > 0 {
+		g.drawGrid(screen)
+	}
+
+	// The paths not being edited are drawn underneath, thinner
+	strokeOp := &vector.StrokeOptions{Width: 3}
+	for i, p := range g.paths {
+		if i == g.current {
+			continue
+		}
+		for j := 0; j+3 < len(p.spline); j += 3 {
+			strokeCurve(screen, g.camera.curve(p.spline[j:j+4]), strokeOp, g.pathColor(i))
+		}
+		for _, k := range p.Points {
+			pt := g.camera.toScreen(bezier.Point{X: k.X, Y: k.Y})
+			vector.DrawFilledCircle(screen, float32(pt.X), float32(pt.Y), pointDiameter/2, pointColor, true)
+		}
+	}
+
+	path := g.path()
+	strokeOp = &vector.StrokeOptions{Width: 1}
 	if g.showNatural {
 		// Calculate natural spline
-		naturalPoints, _ := bezier.NaturalCubicSpline(g.points)
+		naturalPoints, _ := bezier.NaturalCubicSpline(scene.Points(path.Points))
 		if len(naturalPoints) != 0 {
 			for i := 0; i <= (len(naturalPoints)-2)/3; i++ {
 				pts := naturalPoints[i*3 : i*3+4]
-				strokeCurve(screen, &bezier.Bezier{Points: pts}, strokeOp, naturalCurveColor)
+				strokeCurve(screen, g.camera.curve(pts), strokeOp, naturalCurveColor)
 			}
 		}
 	}
 	// Draw bezier curves
 	strokeOp = &vector.StrokeOptions{Width: 5}
-	if len(g.splinePoints) != 0 {
-		for i := 0; i <= (len(g.splinePoints)-2)/3; i++ {
-			pts := g.splinePoints[i*3 : i*3+4]
+	if len(path.spline) != 0 {
+		for i := 0; i <= (len(path.spline)-2)/3; i++ {
+			pts := path.spline[i*3 : i*3+4]
 			curve, err := bezier.NewBezier(false, pts...)
 			if err != nil {
 				log.Fatal(err)
 			}
 			if g.showComb {
-				drawComb(screen, curve)
+				drawComb(screen, curve, g.camera)
 			}
-			strokeCurve(screen, curve, strokeOp, curveColor)
+			strokeCurve(screen, g.camera.curve(pts), strokeOp, g.pathColor(g.current))
 		}
 	}
 
+	if g.showHandles {
+		g.drawHandles(screen)
+	}
+	g.drawGuides(screen)
+	g.drawSelection(screen)
+
 	// Draw points that user can grab
-	for _, pt := range g.points {
+	for i, k := range path.Points {
+		pt := g.camera.toScreen(bezier.Point{X: k.X, Y: k.Y})
 		vector.DrawFilledCircle(screen, float32(pt.X), float32(pt.Y), pointDiameter, pointColor, true)
+		if g.selected(i) {
+			vector.StrokeCircle(screen, float32(pt.X), float32(pt.Y), pointDiameter+3, 2, outlineColor, true)
+		}
 	}
+}
 
-	// UI elements
-	// Draw the toolbar
-	vector.DrawFilledRect(screen, 0, float32(screenHeight-toolbarHeight), float32(screenWidth), float32(toolbarHeight), toolbarColor, true)
+// loadSVGPath parses SVG path data and returns the on-curve points of its
+// first subpath, scaled and centered to fit the canvas above the toolbar.
+func loadSVGPath(d string) ([]bezier.Point, error) {
+	subpaths, err := bezier.ParseSVGPath(d)
+	if err != nil {
+		return nil, err
+	}
+	if len(subpaths) == 0 {
+		return nil, errors.New("svg path: no subpaths")
+	}
+	points := subpaths[0].OnCurvePoints()
+	if len(points) < 2 {
+		return nil, errors.New("svg path: at least 2 on-curve points are required")
+	}
 
-	// Draw slider background
-	vector.DrawFilledRect(screen, float32(sliderX), float32(sliderY), float32(sliderWidth), float32(sliderHeight), sliderBgColor, true)
+	fit := fitCamera(points)
+	for i, p := range points {
+		points[i] = fit.toScreen(p)
+	}
+	return points, nil
+}
 
-	// Draw slider knob
-	knobX := sliderX + int(g.omega*float64(sliderWidth))
-	vector.DrawFilledCircle(screen, float32(knobX), float32(screenHeight-toolbarHeight/2), float32(sliderKnobDiameter/2), sliderKnobColor, true)
-	vector.StrokeCircle(screen, float32(knobX), float32(screenHeight-toolbarHeight/2), float32(sliderKnobDiameter/2), 1, outlineColor, true)
+// writeOutputFiles writes the curves through the knots of the game's paths
+// to the given EPS, PDF and PNG files, skipping any whose name is empty.
+func writeOutputFiles(g *Game, epsFile string, pdfFile string, pngFile string) error {
+	for _, p := range g.paths {
+		var err error
+		if p.spline, err = p.Spline(); err != nil {
+			return err
+		}
+	}
+	printOpts := bezier.PrintOptions{
+		Width:       screenWidth,
+		Height:      screenHeight - toolbarHeight,
+		Background:  backgroundColor,
+		StrokeColor: g.pathColor(g.current),
+		StrokeWidth: 5,
+		Others:      g.otherStrokes(),
+	}
 
-	// Draw comb toggle
-	toggleColor := toggleOnColor
-	if !g.showComb {
-		toggleColor = toggleOffColor
+	var buf bytes.Buffer
+	if epsFile != "" {
+		if err := bezier.WriteEPS(&buf, g.path().spline, printOpts); err != nil {
+			return err
+		}
+		if err := saveFile(epsFile, "application/postscript", buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	if pdfFile != "" {
+		buf.Reset()
+		if err := bezier.WritePDF(&buf, g.path().spline, printOpts); err != nil {
+			return err
+		}
+		if err := saveFile(pdfFile, "application/pdf", buf.Bytes()); err != nil {
+			return err
+		}
 	}
-	vector.DrawFilledCircle(screen, float32(combToggleX), float32(combToggleY), float32(sliderHeight), toggleColor, true)
-	vector.StrokeCircle(screen, float32(combToggleX), float32(combToggleY), float32(sliderHeight), 1, outlineColor, true)
+	if pngFile != "" {
+		buf.Reset()
+		spline, opts := g.renderOptions()
+		if err := bezier.WritePNG(&buf, spline, opts); err != nil {
+			return err
+		}
+		if err := saveFile(pngFile, "image/png", buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	// Draw natural toggle
-	toggleColor = toggleOnColor
-	if !g.showNatural {
-		toggleColor = toggleOffColor
+// renderOptions returns the curve being edited, along with options to render
+// it, the other curves on screen and whichever overlays are enabled the way
+// the demo draws them.
+func (g *Game) renderOptions() ([]bezier.Point, bezier.RenderOptions) {
+	spline, knots, clr, others := g.path().spline, scene.Points(g.path().Points), g.pathColor(g.current), g.otherStrokes()
+	if g.freehand {
+		spline, knots, clr, others = g.fittedPoints, nil, curveColor, nil
+		for i := 0; i < len(g.fittedPoints); i += 3 {
+			knots = append(knots, g.fittedPoints[i])
+		}
 	}
-	vector.DrawFilledCircle(screen, float32(naturalToggleX), float32(naturalToggleY), float32(sliderHeight), toggleColor, true)
-	vector.StrokeCircle(screen, float32(naturalToggleX), float32(naturalToggleY), float32(sliderHeight), 1, outlineColor, true)
 
-	textOp := &text.DrawOptions{}
-	textOp.ColorScale.ScaleWithColor(textColor)
-	// Draw omega value
-	omegaText := fmt.Sprintf("w = %.2f", g.omega)
-	omegaTextWidth := textWidth(omegaText, textFont)
-	textOp.GeoM.Translate(float64(sliderX-omegaTextWidth-padding), float64(sliderY-3))
-	text.Draw(screen, omegaText, text.NewGoXFace(textFont), textOp)
-
-	// Draw "Show Comb" label next to toggle
-	textOp = &text.DrawOptions{}
-	textOp.ColorScale.ScaleWithColor(textColor)
-	textOp.GeoM.Translate(float64(combToggleX+toggleDiameter), float64(combToggleY-sliderHeight/2-3))
-	text.Draw(screen, "Show Comb", text.NewGoXFace(textFont), textOp)
+	opts := bezier.RenderOptions{
+		Width:       screenWidth,
+		Height:      screenHeight - toolbarHeight,
+		Background:  backgroundColor,
+		CurveColor:  clr,
+		CurveWidth:  5,
+		Others:      others,
+		Knots:       knots,
+		KnotColor:   pointColor,
+		KnotRadius:  pointDiameter,
+		Comb:        g.showComb,
+		CombSpacing: PIXELS_PER_COMB_TOOTH,
+		CombScale:   COMB_SCALE,
+		CombColor: func(i int, teeth int) color.Color {
+			return getCombColors(teeth)[i]
+		},
+	}
+	if g.showNatural && !g.freehand {
+		opts.Natural, _ = bezier.NaturalCubicSpline(scene.Points(g.path().Points))
+		opts.NaturalColor = naturalCurveColor
+	}
+	return spline, opts
+}
 
-	// Draw "Show Natural" label next to toggle
-	textOp = &text.DrawOptions{}
-	textOp.ColorScale.ScaleWithColor(textColor)
-	textOp.GeoM.Translate(float64(naturalToggleX+toggleDiameter), float64(naturalToggleY-sliderHeight/2-3))
-	text.Draw(screen, "Show Natural", text.NewGoXFace(textFont), textOp)
+// otherStrokes returns the splines of the paths not being edited, in their
+// colors.
+func (g *Game) otherStrokes() []bezier.Stroke {
+	var strokes []bezier.Stroke
+	for i, p := range g.paths {
+		if i != g.current {
+			strokes = append(strokes, bezier.Stroke{Spline: p.spline, Color: g.pathColor(i)})
+		}
+	}
+	return strokes
+}
 
+// exportSVG saves the curve currently on screen, along with whichever
+// overlays are enabled, as an SVG document.
+func (g *Game) exportSVG() error {
+	spline, opts := g.renderOptions()
+	var buf bytes.Buffer
+	if err := bezier.WriteSVG(&buf, spline, opts); err != nil {
+		return err
+	}
+	return saveFile(svgExportName, "image/svg+xml", buf.Bytes())
+}
+
+// scene returns the current state of the demo as a scene.
+func (g *Game) scene() *scene.Scene {
+	s := &scene.Scene{Version: scene.Version, View: g.view()}
+	for _, p := range g.paths {
+		s.Paths = append(s.Paths, p.Path)
+	}
+	return s
+}
+
+// setScene replaces the state of the demo with that of s.
+func (g *Game) setScene(s *scene.Scene) {
+	g.paths = nil
+	for _, p := range s.Paths {
+		g.paths = append(g.paths, &path{Path: p})
+	}
+	g.current = 0
+	g.selection = nil
+	g.setView(s.View)
+}
+
+// saveScene writes the current state of the demo to the scene file.
+func (g *Game) saveScene() error {
+	data, err := scene.Marshal(g.scene())
+	if err != nil {
+		return err
+	}
+	return writeScene(g.sceneFile, data)
+}
+
+// loadScene replaces the state of the demo with the contents of the scene
+// file.
+func (g *Game) loadScene() error {
+	data, err := readScene(g.sceneFile)
+	if err != nil {
+		return err
+	}
+	s, err := scene.Read(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	g.setScene(s)
+	return nil
 }
 
 func textWidth(s string, face font.Face) int {
@@ -290,19 +586,17 @@ func strokeCurve(dst *ebiten.Image, curve *bezier.Bezier, strokeOp *vector.Strok
 
 const PIXELS_PER_COMB_TOOTH = 5
 
-func drawComb(dst *ebiten.Image, curve *bezier.Bezier) {
-	length := curve.Length()
-	teeth := math.Floor(length / PIXELS_PER_COMB_TOOTH)
-	colors := getCombColors(int(teeth))
-	step := 1 / teeth
-	for i := 0.0; i < teeth; i++ {
-		t := i * step
-		p := curve.Get(t)
-		n := curve.Normal(t)
-		kr := curve.Curvature(t)
-		p2 := bezier.Point{X: p.X + n.X*kr.K*-1500, Y: p.Y + n.Y*kr.K*-1500}
-		combColor := colors[int(i)]
-		// combColor := red
+// COMB_SCALE converts curvature into the length of a comb tooth in pixels.
+const COMB_SCALE = -1500
+
+// drawComb draws the curvature comb of a curve in world coordinates, keeping
+// the spacing and length of its teeth the same on screen at any zoom.
+func drawComb(dst *ebiten.Image, curve *bezier.Bezier, cam camera) {
+	comb := curve.Comb(PIXELS_PER_COMB_TOOTH/cam.zoom, COMB_SCALE/(cam.zoom*cam.zoom))
+	colors := getCombColors(len(comb))
+	for i, tooth := range comb {
+		p, p2 := cam.toScreen(tooth[0]), cam.toScreen(tooth[1])
+		combColor := colors[i]
 		vector.StrokeLine(dst, float32(p.X), float32(p.Y), float32(p2.X), float32(p2.Y), 1, combColor, true)
 	}
 }
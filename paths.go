@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"slices"
+
+	"github.com/braheezy/hobby-spline/pkg/bezier"
+	"github.com/braheezy/hobby-spline/pkg/scene"
+)
+
+// path is one of the curves in the scene, along with the spline last fitted
+// to its knots.
+type path struct {
+	scene.Path
+	spline []bezier.Point
+}
+
+// path returns the path being edited.
+func (g *Game) path() *path {
+	return g.paths[g.current]
+}
+
+// selectPath makes p the path being edited, deselecting the knots selected
+// on the one before.
+func (g *Game) selectPath(p *path) {
+	if i := slices.Index(g.paths, p); i >= 0 && i != g.current {
+		g.current = i
+		g.selection = nil
+	}
+}
+
+// pathAt returns the index of the path with a knot or curve at screen
+// position x, y, or -1 if there is none. The path being edited comes first,
+// including its handles if they're shown, then the others from the top down.
+func (g *Game) pathAt(x, y int) int {
+	order := []int{g.current}
+	for i := len(g.paths) - 1; i >= 0; i-- {
+		if i != g.current {
+			order = append(order, i)
+		}
+	}
+
+	at := g.camera.toWorld(float64(x), float64(y))
+	for _, i := range order {
+		p := g.paths[i]
+		if p.knotAt(g.camera, x, y) >= 0 {
+			return i
+		}
+		if _, _, ok := p.curveAt(g.camera, at); ok {
+			return i
+		}
+		if _, _, ok := p.handleAt(g.camera, x, y); ok && i == g.current && g.showHandles {
+			return i
+		}
+	}
+	return -1
+}
+
+// pathColor returns the color the path at index i is drawn in: its own, or
+// failing that one from pathColors.
+func (g *Game) pathColor(i int) color.Color {
+	if c, err := scene.ParseColor(g.paths[i].Color); err == nil {
+		return c
+	}
+	return pathColors[i%len(pathColors)]
+}
+
+// insertPath inserts p into the scene at index i and selects it.
+func (g *Game) insertPath(i int, p *path) {
+	g.paths = slices.Insert(g.paths, i, p)
+	g.current = i
+	g.selection = nil
+}
+
+// deletePath deletes the path at index i, selecting the one before it.
+func (g *Game) deletePath(i int) {
+	g.paths = slices.Delete(g.paths, i, i+1)
+	g.current = max(0, min(i-1, len(g.paths)-1))
+	g.selection = nil
+}
+
+// newPath returns a path of a few knots in the middle of the view, in the
+// next color from pathColors.
+func (g *Game) newPath() *path {
+	p := &path{Path: scene.DefaultPath()}
+	p.Color = scene.FormatColor(pathColors[len(g.paths)%len(pathColors)])
+	center := g.camera.toWorld(screenWidth/2, (screenHeight-toolbarHeight)/2)
+	size := newPathSize / g.camera.zoom
+	p.Points = []scene.Knot{
+		{X: center.X - size, Y: center.Y + size/2},
+		{X: center.X, Y: center.Y - size/2},
+		{X: center.X + size, Y: center.Y + size/2},
+	}
+	return p
+}
+
+// updatePathList declares the list of paths in the top left corner, which
+// selects the path to edit, and the widgets to add, delete and close paths.
+func (g *Game) updatePathList() {
+	lineHeight := widgetHeight + widgetGap/2
+	rows := len(g.paths) + 2
+	g.ui.panel(image.Rect(padding/2, padding/2, padding+pathListWidth+padding/2, padding+rows*lineHeight-widgetGap/2+padding/2), panelColor)
+
+	r := image.Rect(padding, padding, padding+pathListWidth, padding+widgetHeight)
+	for i, p := range g.paths {
+		if g.ui.listItem(fmt.Sprintf("path%d", i), r, fmt.Sprintf("Path %d", i+1), g.pathColor(i), i == g.current) {
+			g.endDrag()
+			g.selectPath(p)
+		}
+		r = r.Add(image.Pt(0, lineHeight))
+	}
+
+	buttons := row{x: r.Min.X, y: r.Min.Y, height: widgetHeight}
+	buttonWidth := (pathListWidth - widgetGap) / 2
+	if g.ui.button("newPath", buttons.next(buttonWidth), "New") {
+		g.endDrag()
+		g.apply(&addPath{index: len(g.paths), path: g.newPath()})
+	}
+	// There's always at least one path to edit
+	if g.ui.button("deletePath", buttons.next(buttonWidth), "Delete") && len(g.paths) > 1 {
+		g.endDrag()
+		g.apply(&removePath{index: g.current, path: g.path()})
+	}
+	r = r.Add(image.Pt(0, lineHeight))
+
+	// Natural splines can't be closed, and closed curves need a knot for
+	// each side of a triangle at least
+	path := g.path()
+	if path.Algorithm == scene.AlgorithmNatural {
+		return
+	}
+	r.Max.X = r.Min.X + toggleWidth("Closed")
+	if g.ui.toggle("closed", r, "Closed", path.Closed) && len(path.Points) >= minKnots {
+		g.endDrag()
+		to := path.Path
+		to.Closed = !to.Closed
+		to.Points = pruneHandles(slices.Clone(to.Points), to.Closed)
+		g.apply(&setPath{path: path, from: path.Path, to: to})
+	}
+}
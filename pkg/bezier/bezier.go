@@ -3,6 +3,7 @@ package bezier
 import (
 	"errors"
 	"fmt"
+	"math"
 )
 
 type CurvatureVector struct {
@@ -40,12 +41,6 @@ func NewBezier(use3d bool, points ...Point) (*Bezier, error) {
 	} else if count > 12 {
 		return nil, errors.New("at most 12 points are allowed")
 	}
-	if use3d {
-		if count != 8 && count != 9 && count != 12 {
-			return nil, fmt.Errorf("3D Bezier curves require 8, 9, or 12 points, got %v", count)
-		}
-	}
-
 	b := &Bezier{
 		Points: points,
 		order:  len(points) - 1,
@@ -81,17 +76,146 @@ func (b *Bezier) Get(t float64) Point {
 	return compute(t, b.Points, b.threeDimensional)
 }
 
+// Normal returns the unit normal at t. For 2D curves this is the tangent
+// rotated a quarter turn counter-clockwise; for 3D curves it is the principal
+// normal of the Frenet frame.
 func (b *Bezier) Normal(t float64) Point {
 	if b.threeDimensional {
-		return normal3(t, b.derivative)
+		n := b.Frenet(t).Normal
+		n.t = t
+		return n
 	} else {
 		return normal2(t, b.derivative)
 	}
 }
+
+// Project finds the point on the curve closest to p, returning it along with
+// its parameter t.
+func (b *Bezier) Project(p Point) (Point, float64) {
+	return project(p, b.Points, b.threeDimensional)
+}
+
 func (b *Bezier) Curvature(t float64) CurvatureVector {
 	return curvature(t, b.dpoints[0], b.dpoints[1], b.threeDimensional, false)
 }
 
+// Segments splits a spline in the 3n - 2 layout returned by CreateHobbySpline
+// into its individual cubic Bézier curves.
+func Segments(spline []Point) ([]*Bezier, error) {
+	return segments(spline, false)
+}
+
+// Segments3D is like Segments, but for splines returned by
+// CreateHobbySpline3D. The curves it returns are three-dimensional.
+func Segments3D(spline []Point) ([]*Bezier, error) {
+	return segments(spline, true)
+}
+
+func segments(spline []Point, use3d bool) ([]*Bezier, error) {
+	if len(spline) < 4 || (len(spline)-1)%3 != 0 {
+		return nil, fmt.Errorf("a spline needs 3n + 1 points, got %v", len(spline))
+	}
+	var curves []*Bezier
+	for i := 0; i+3 < len(spline); i += 3 {
+		pts := make([]Point, 4)
+		copy(pts, spline[i:i+4])
+		curve, err := NewBezier(use3d, pts...)
+		if err != nil {
+			return nil, err
+		}
+		curves = append(curves, curve)
+	}
+	return curves, nil
+}
+
+// Split splits the curve at t into two curves of the same order, using de
+// Casteljau's algorithm.
+func (b *Bezier) Split(t float64) (*Bezier, *Bezier) {
+	n := len(b.Points)
+	left := make([]Point, n)
+	right := make([]Point, n)
+
+	pts := make([]Point, n)
+	for i, p := range b.Points {
+		pts[i] = Point{X: p.X, Y: p.Y, Z: p.Z}
+	}
+	for level := 0; level < n; level++ {
+		left[level] = pts[0]
+		right[n-1-level] = pts[n-1-level]
+		for i := 0; i < n-1-level; i++ {
+			pts[i] = Point{
+				X: pts[i].X + (pts[i+1].X-pts[i].X)*t,
+				Y: pts[i].Y + (pts[i+1].Y-pts[i].Y)*t,
+				Z: pts[i].Z + (pts[i+1].Z-pts[i].Z)*t,
+			}
+		}
+	}
+
+	l, _ := NewBezier(b.threeDimensional, left...)
+	r, _ := NewBezier(b.threeDimensional, right...)
+	return l, r
+}
+
+// maxFlattenDepth bounds the recursion of Flatten for degenerate curves.
+const maxFlattenDepth = 16
+
+// Flatten approximates the curve with a polyline whose points are all on the
+// curve, and which is nowhere further than tolerance from it. The first and
+// last points of the polyline are the end points of the curve.
+func (b *Bezier) Flatten(tolerance float64) []Point {
+	first := b.Points[0]
+	return append([]Point{{X: first.X, Y: first.Y, Z: first.Z}}, flatten(b, tolerance, 0)...)
+}
+
+// flatten returns the polyline for b, excluding its first point.
+func flatten(b *Bezier, tolerance float64, depth int) []Point {
+	last := b.Points[len(b.Points)-1]
+	if depth >= maxFlattenDepth || isFlat(b.Points, tolerance) {
+		return []Point{{X: last.X, Y: last.Y, Z: last.Z}}
+	}
+	l, r := b.Split(0.5)
+	return append(flatten(l, tolerance, depth+1), flatten(r, tolerance, depth+1)...)
+}
+
+// isFlat reports whether every control point is within tolerance of the
+// chord, which bounds the distance of the curve from it. Distances are to the
+// chord itself rather than the line through it, since curves like cusps can
+// overshoot its ends.
+func isFlat(points []Point, tolerance float64) bool {
+	first, last := points[0], points[len(points)-1]
+	chord := vSub3(last, first)
+	chordLength := chord.Length3()
+	for _, p := range points[1 : len(points)-1] {
+		v := vSub3(p, first)
+		var t float64
+		if chordLength != 0 {
+			t = math.Max(0, math.Min(1, vDot3(v, chord)/(chordLength*chordLength)))
+		}
+		if vSub3(v, Scale3(chord, t)).Length3() > tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// Comb returns the teeth of the curvature comb along the curve, one every
+// spacing units of length. Each tooth starts on the curve and extends along
+// the normal by the curvature there multiplied by scale.
+func (b *Bezier) Comb(spacing float64, scale float64) [][2]Point {
+	teeth := math.Floor(b.Length() / spacing)
+	step := 1 / teeth
+	var comb [][2]Point
+	for i := 0.0; i < teeth; i++ {
+		t := i * step
+		p := b.Get(t)
+		n := b.Normal(t)
+		kr := b.Curvature(t)
+		p2 := Point{X: p.X + n.X*kr.K*scale, Y: p.Y + n.Y*kr.K*scale}
+		comb = append(comb, [2]Point{p, p2})
+	}
+	return comb
+}
+
 func (b *Bezier) update() {
 	b.setDpoints()
 }
@@ -105,8 +229,9 @@ func (b *Bezier) setDpoints() {
 		c := len(points) - 1
 		for i := 0; i < c; i++ {
 			dpt := Point{
-				X: float64(c) * (points[i+1].X - points[i].X),
-				Y: float64(c) * (points[i+1].Y - points[i].Y),
+				X:                float64(c) * (points[i+1].X - points[i].X),
+				Y:                float64(c) * (points[i+1].Y - points[i].Y),
+				threeDimensional: b.threeDimensional,
 			}
 			if b.threeDimensional {
 				dpt.Z = float64(c) * (points[i+1].Z - points[i].Z)
@@ -0,0 +1,294 @@
+package bezier
+
+import (
+	"errors"
+	"math"
+)
+
+// maxBiarcDepth bounds how often a curve is split in two when approximating
+// it with biarcs.
+const maxBiarcDepth = 12
+
+// biarcSamples is the number of points along a curve, and along each arc,
+// that are checked against the tolerance.
+const biarcSamples = 16
+
+// inflectionSamples is the number of intervals the curvature is sampled at
+// when looking for inflections.
+const inflectionSamples = 32
+
+// Arc is a circular arc, as produced by Biarcs.
+//
+// Pieces of a curve that are straight are returned as arcs of infinite
+// radius. These have no meaningful Center or angles, and should be drawn as
+// a line from Start to End; see IsLine.
+type Arc struct {
+	Start  Point
+	End    Point
+	Center Point
+	Radius float64
+	// StartAngle and EndAngle are the directions of Start and End as seen
+	// from Center, in radians counter-clockwise from the X axis.
+	StartAngle float64
+	EndAngle   float64
+	// CounterClockwise is set if the arc turns from the X axis towards the
+	// Y axis. On screen, where Y points down, that appears clockwise.
+	CounterClockwise bool
+}
+
+// IsLine reports whether the arc is a straight line.
+func (a Arc) IsLine() bool {
+	return math.IsInf(a.Radius, 1)
+}
+
+// Point returns the point a fraction t of the way along the arc.
+func (a Arc) Point(t float64) Point {
+	if a.IsLine() {
+		return vAdd(a.Start, Scale(vSub(a.End, a.Start), t))
+	}
+	return vAdd(a.Center, Rotate(vSub(a.Start, a.Center), t*a.Sweep()))
+}
+
+// Sweep returns the signed angle the arc turns through, positive for
+// counter-clockwise arcs.
+func (a Arc) Sweep() float64 {
+	if a.IsLine() {
+		return 0
+	}
+	angle := a.EndAngle - a.StartAngle
+	if a.CounterClockwise && angle < 0 {
+		angle += 2 * math.Pi
+	} else if !a.CounterClockwise && angle > 0 {
+		angle -= 2 * math.Pi
+	}
+	return angle
+}
+
+// distance returns the distance from p to the circle or line the arc lies on.
+func (a Arc) distance(p Point) float64 {
+	if a.IsLine() {
+		chord := vSub(a.End, a.Start)
+		l := chord.Length()
+		if l == 0 {
+			return vDistance(p, a.Start)
+		}
+		return math.Abs(chord.X*(p.Y-a.Start.Y)-chord.Y*(p.X-a.Start.X)) / l
+	}
+	return math.Abs(vDistance(p, a.Center) - a.Radius)
+}
+
+// Biarcs approximates a spline in the 3n - 2 layout of CreateHobbySpline with
+// circular arcs, none of which strays further than tolerance from it. The
+// arcs are listed in order, and each starts where the previous one ends.
+// Where the spline is smooth, so is the sequence of arcs, except around
+// cusps and the like, which are approximated with lines.
+func Biarcs(spline []Point, tolerance float64) ([]Arc, error) {
+	if tolerance <= 0 {
+		return nil, errors.New("tolerance must be positive")
+	}
+	curves, err := Segments(spline)
+	if err != nil {
+		return nil, err
+	}
+	var arcs []Arc
+	for _, curve := range curves {
+		arcs = append(arcs, curve.Biarcs(tolerance)...)
+	}
+	return arcs, nil
+}
+
+// Biarcs approximates the curve with circular arcs that are nowhere further
+// than tolerance from it.
+//
+// The curve is first split at its inflections, then each piece is replaced by
+// a biarc: two arcs that match the direction of the piece at both ends, and
+// each other's where they meet. Pieces whose biarc isn't close enough are
+// split in half and tried again, up to a limit, beyond which they're
+// approximated with lines instead.
+func (b *Bezier) Biarcs(tolerance float64) []Arc {
+	var arcs []Arc
+	t0 := 0.0
+	rest := b
+	for _, t := range b.Inflections() {
+		// Split the remainder of the curve, whose parameter is rescaled
+		left, right := rest.Split((t - t0) / (1 - t0))
+		arcs = left.biarcsTo(arcs, tolerance, 0)
+		rest, t0 = right, t
+	}
+	return rest.biarcsTo(arcs, tolerance, 0)
+}
+
+// Inflections returns the values of t, in increasing order, where the curve
+// changes which way it turns, i.e. where its curvature changes sign.
+func (b *Bezier) Inflections() []float64 {
+	var inflections []float64
+	prevT, prevK := 0.0, b.Curvature(0).K
+	for i := 1; i <= inflectionSamples; i++ {
+		t := float64(i) / inflectionSamples
+		k := b.Curvature(t).K
+		if k == 0 && i < inflectionSamples {
+			// Wait for the sign on the other side
+			continue
+		}
+		if (prevK < 0 && k > 0) || (prevK > 0 && k < 0) {
+			inflections = append(inflections, b.bisectInflection(prevT, t))
+		}
+		if k != 0 {
+			prevT, prevK = t, k
+		}
+	}
+	return inflections
+}
+
+// bisectInflection narrows down an inflection known to lie between lo and hi.
+func (b *Bezier) bisectInflection(lo, hi float64) float64 {
+	loK := b.Curvature(lo).K
+	for i := 0; i < 32 && hi-lo > 1e-9; i++ {
+		mid := (lo + hi) / 2
+		k := b.Curvature(mid).K
+		if k == 0 {
+			return mid
+		}
+		if (k < 0) == (loK < 0) {
+			lo, loK = mid, k
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+func (b *Bezier) biarcsTo(arcs []Arc, tolerance float64, depth int) []Arc {
+	first, last := b.Points[0], b.Points[len(b.Points)-1]
+	pair := biarc(first, b.tangent(0), last, b.tangent(1))
+	if b.biarcError(pair) <= tolerance {
+		return append(arcs, pair[0], pair[1])
+	}
+	if depth >= maxBiarcDepth {
+		// Some pieces won't fit a biarc however small they get, like those
+		// around cusps, so they're approximated with lines instead
+		points := b.Flatten(tolerance)
+		for i := 1; i < len(points); i++ {
+			arcs = append(arcs, Arc{Start: points[i-1], End: points[i], Radius: math.Inf(1)})
+		}
+		return arcs
+	}
+	left, right := b.Split(0.5)
+	arcs = left.biarcsTo(arcs, tolerance, depth+1)
+	return right.biarcsTo(arcs, tolerance, depth+1)
+}
+
+// biarcError estimates the largest distance between the curve and a biarc
+// approximating it, in both directions.
+func (b *Bezier) biarcError(pair [2]Arc) float64 {
+	maxError := 0.0
+	for i := 1; i < biarcSamples; i++ {
+		t := float64(i) / biarcSamples
+
+		// From the curve to whichever arc it's alongside
+		p := b.Get(t)
+		a := pair[0]
+		if vDot(vSub(p, pair[0].End), vSub(pair[1].End, pair[0].End)) > 0 {
+			a = pair[1]
+		}
+		maxError = math.Max(maxError, a.distance(p))
+
+		// From the arcs back to the curve
+		for _, a := range pair {
+			q := a.Point(t)
+			maxError = math.Max(maxError, vDistance(q, b.closest(q)))
+		}
+	}
+	return maxError
+}
+
+// closest returns the point of the curve closest to p. It refines Project
+// with a few steps of Newton's method, since biarcs of tight tolerances are
+// checked against short pieces of curve, where its samples are too coarse.
+func (b *Bezier) closest(p Point) Point {
+	best, t := b.Project(p)
+	for i := 0; i < 4; i++ {
+		// Find where the curve is perpendicular to the way to p
+		v := vSub(b.Get(t), p)
+		d := b.derivative(t)
+		slope := vDot(d, d) + vDot(v, compute(t, b.dpoints[1], false))
+		if slope <= 0 {
+			break
+		}
+		t = math.Max(0, math.Min(1, t-vDot(v, d)/slope))
+	}
+	// Newton's method can wander off to a worse point near cusps
+	if q := b.Get(t); vDistance(p, q) < vDistance(p, best) {
+		return q
+	}
+	return best
+}
+
+// biarc returns two arcs joining p0 to p1 that leave p0 in the direction t0,
+// arrive at p1 in the direction t1, and meet with a common tangent. Both
+// directions must be unit vectors.
+//
+// Of the many such pairs, this picks the one where the tangent lines at p0
+// and p1 reach equally far to the point the arcs meet, following Ryan
+// Juckett's "Biarc Interpolation".
+func biarc(p0, t0, p1, t1 Point) [2]Arc {
+	t0, t1 = Point{X: t0.X, Y: t0.Y}, Point{X: t1.X, Y: t1.Y}
+	v := vSub(p1, p0)
+	t := vAdd(t0, t1)
+	denom := 2 * (1 - vDot(t0, t1))
+
+	var d float64
+	if denom < 1e-12 {
+		// The end tangents are parallel
+		if vDot(v, t1) == 0 {
+			// ...and perpendicular to the chord, so the arcs are two
+			// semicircles
+			d = v.Length() / 4
+		} else {
+			d = vDot(v, v) / (4 * vDot(v, t1))
+		}
+	} else {
+		vt := vDot(v, t)
+		d = (-vt + math.Sqrt(vt*vt+denom*vDot(v, v))) / denom
+	}
+
+	// The arcs meet halfway between the ends of the tangent lines
+	q0 := vAdd(p0, Scale(t0, d))
+	q1 := vSub(p1, Scale(t1, d))
+	mid := Scale(vAdd(q0, q1), 0.5)
+
+	// The second arc is found backwards from p1, then turned around
+	return [2]Arc{tangentArc(p0, t0, mid), tangentArc(p1, Scale(t1, -1), mid).reversed()}
+}
+
+// tangentArc returns the arc from start to end that leaves start in the
+// direction tangent.
+func tangentArc(start, tangent, end Point) Arc {
+	chord := vSub(end, start)
+	normal := Point{X: -tangent.Y, Y: tangent.X}
+	side := vDot(normal, chord)
+	if math.Abs(side) <= 1e-9*chord.Length() {
+		return Arc{Start: start, End: end, Radius: math.Inf(1)}
+	}
+
+	// The center is on the normal, equally far from both ends
+	r := vDot(chord, chord) / (2 * side)
+	center := vAdd(start, Scale(normal, r))
+	return Arc{
+		Start:            start,
+		End:              end,
+		Center:           center,
+		Radius:           math.Abs(r),
+		StartAngle:       math.Atan2(start.Y-center.Y, start.X-center.X),
+		EndAngle:         math.Atan2(end.Y-center.Y, end.X-center.X),
+		CounterClockwise: r > 0,
+	}
+}
+
+// reversed returns the arc running the other way.
+func (a Arc) reversed() Arc {
+	a.Start, a.End = a.End, a.Start
+	a.StartAngle, a.EndAngle = a.EndAngle, a.StartAngle
+	a.CounterClockwise = !a.CounterClockwise
+	return a
+}
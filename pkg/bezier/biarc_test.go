@@ -0,0 +1,86 @@
+package bezier
+
+import (
+	"math"
+	"testing"
+)
+
+// curveDistance returns the distance from p to the curve. Every local minimum
+// of the distance to a dense set of samples is refined, since near cusps the
+// nearest sample may be on the wrong branch.
+func curveDistance(p Point, b *Bezier) float64 {
+	const samples = 20000
+	dist := func(t float64) float64 { return vDistance(p, b.Get(t)) }
+	sampled := make([]float64, samples+1)
+	for i := range sampled {
+		sampled[i] = dist(float64(i) / samples)
+	}
+
+	best := math.Inf(1)
+	for i, d := range sampled {
+		if (i > 0 && sampled[i-1] < d) || (i < samples && sampled[i+1] < d) {
+			continue
+		}
+		lo, hi := math.Max(0, float64(i-1)/samples), math.Min(1, float64(i+1)/samples)
+		for j := 0; j < 60; j++ {
+			m1, m2 := lo+(hi-lo)/3, hi-(hi-lo)/3
+			if dist(m1) < dist(m2) {
+				hi = m2
+			} else {
+				lo = m1
+			}
+		}
+		best = math.Min(best, dist((lo+hi)/2))
+	}
+	return best
+}
+
+// checkArcs fails the test unless the arcs run continuously along the whole
+// curve, and stray no further than tolerance from it.
+func checkArcs(t *testing.T, name string, arcs []Arc, b *Bezier, tolerance float64) {
+	t.Helper()
+	first, last := b.Points[0], b.Points[len(b.Points)-1]
+	if vDistance(arcs[0].Start, first) != 0 || vDistance(arcs[len(arcs)-1].End, last) != 0 {
+		t.Fatalf("%s: arcs run from %v to %v, want %v to %v", name, arcs[0].Start, arcs[len(arcs)-1].End, first, last)
+	}
+	worst := 0.0
+	for i, a := range arcs {
+		if i > 0 && vDistance(a.Start, arcs[i-1].End) > 1e-9 {
+			t.Fatalf("%s: arc %d starts at %v, but the one before ends at %v", name, i, a.Start, arcs[i-1].End)
+		}
+		for _, u := range []float64{0.25, 0.5, 0.75} {
+			worst = math.Max(worst, curveDistance(a.Point(u), b))
+		}
+	}
+	if worst > tolerance {
+		t.Errorf("%s: %d arcs stray %g from the curve, more than the tolerance of %g", name, len(arcs), worst, tolerance)
+	}
+}
+
+func TestBiarcsTolerance(t *testing.T) {
+	curves := map[string][]Point{
+		"arch":   {{X: 0, Y: 0}, {X: 20, Y: 80}, {X: 80, Y: 80}, {X: 100, Y: 0}},
+		"corner": {{X: 0, Y: 0}, {X: 100, Y: 0}, {X: 100, Y: 0}, {X: 100, Y: 100}},
+		"s bend": {{X: 0, Y: 0}, {X: 40, Y: 60}, {X: 60, Y: -60}, {X: 100, Y: 0}},
+		"cusp":   {{X: 0, Y: 0}, {X: 100, Y: 100}, {X: -12.5, Y: 37.5}, {X: 100, Y: 0}},
+	}
+	for name, points := range curves {
+		b, err := NewBezier(false, points...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tolerance := range []float64{1, 0.1, 1e-3} {
+			checkArcs(t, name, b.Biarcs(tolerance), b, tolerance)
+		}
+	}
+}
+
+func TestBiarcsDepthLimit(t *testing.T) {
+	// An S bend has no biarc close to it, so once pieces can't be split any
+	// further they must be approximated some other way
+	b, err := NewBezier(false, Point{X: 0, Y: 0}, Point{X: 40, Y: 60}, Point{X: 60, Y: -60}, Point{X: 100, Y: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkArcs(t, "s bend", b.biarcsTo(nil, 0.1, maxBiarcDepth), b, 0.1)
+}
@@ -0,0 +1,270 @@
+package bezier
+
+import (
+	"errors"
+	"math"
+)
+
+// maxFitIterations is the number of Newton-Raphson reparameterization passes
+// attempted before a piece of the input is split in two.
+const maxFitIterations = 4
+
+// FitCurve: given a dense, possibly noisy polyline (e.g. a captured mouse or
+// touch stroke), fit a small number of cubic Bézier curves to it such that no
+// sample is further than tolerance away from the fitted spline.
+//
+// This is Philip J. Schneider's algorithm from Graphics Gems, "An Algorithm
+// for Automatically Fitting Digitized Curves". Before fitting, the samples are
+// split at corners, i.e. samples where the stroke turns by more than
+// cornerAngle radians. Pieces on either side of a corner are fitted
+// independently, so the result has a sharp point there instead of a rounded
+// one. A cornerAngle of 0 disables corner detection.
+//
+// The output uses the same layout as CreateHobbySpline: knots interspersed
+// with pairs of handle points, 3n - 2 points for n knots.
+func FitCurve(samples []Point, tolerance float64, cornerAngle float64) ([]Point, error) {
+	if tolerance <= 0 {
+		return nil, errors.New("tolerance must be positive")
+	}
+
+	// Successive duplicate samples carry no information and break the
+	// tangent estimates, so drop them up front
+	var points []Point
+	for _, p := range samples {
+		if len(points) == 0 || vDistance(points[len(points)-1], p) > 0 {
+			points = append(points, Point{X: p.X, Y: p.Y})
+		}
+	}
+	if len(points) < 2 {
+		return nil, errors.New("not enough points")
+	}
+
+	result := []Point{points[0]}
+	corners := findCorners(points, cornerAngle)
+	for i := 0; i < len(corners)-1; i++ {
+		piece := points[corners[i] : corners[i+1]+1]
+		tHat1 := leftTangent(piece)
+		tHat2 := rightTangent(piece)
+		fitCubic(piece, tHat1, tHat2, tolerance*tolerance, &result)
+	}
+
+	return result, nil
+}
+
+// findCorners returns the indices of the samples the polyline should be split
+// at. The first and last samples are always included.
+func findCorners(points []Point, cornerAngle float64) []int {
+	last := len(points) - 1
+	corners := []int{0}
+	if cornerAngle <= 0 {
+		return append(corners, last)
+	}
+
+	// Compare directions over a small window of neighbours rather than the
+	// immediate ones, otherwise every jittery sample looks like a corner
+	const window = 3
+	turn := make([]float64, len(points))
+	for i := 1; i < last; i++ {
+		in := vSub(points[i], points[max(0, i-window)])
+		out := vSub(points[min(last, i+window)], points[i])
+		turn[i] = math.Abs(vAngleBetween(in, out))
+	}
+
+	for i := 1; i < last; i++ {
+		if turn[i] < cornerAngle {
+			continue
+		}
+		// Only keep the sharpest sample of a run of candidates
+		isPeak := true
+		for j := max(1, i-window); j <= min(last-1, i+window); j++ {
+			if turn[j] > turn[i] || (turn[j] == turn[i] && j < i) {
+				isPeak = false
+				break
+			}
+		}
+		if isPeak && i-corners[len(corners)-1] > 1 {
+			corners = append(corners, i)
+		}
+	}
+	if last-corners[len(corners)-1] < 2 && len(corners) > 1 {
+		corners = corners[:len(corners)-1]
+	}
+	return append(corners, last)
+}
+
+// leftTangent estimates the unit tangent leaving the first sample.
+func leftTangent(points []Point) Point {
+	return Normalize(vSub(points[min(len(points)-1, 2)], points[0]))
+}
+
+// rightTangent estimates the unit tangent leaving the last sample, pointing
+// back into the polyline.
+func rightTangent(points []Point) Point {
+	last := len(points) - 1
+	return Normalize(vSub(points[max(0, last-2)], points[last]))
+}
+
+// fitCubic fits a cubic to points, splitting it recursively until every piece
+// is within sqrt(errorSq) of its samples. The handles and end knot of each
+// fitted cubic are appended to result.
+func fitCubic(points []Point, tHat1, tHat2 Point, errorSq float64, result *[]Point) {
+	first, last := points[0], points[len(points)-1]
+
+	// With only two points there's nothing to fit; use a heuristic
+	if len(points) == 2 {
+		dist := vDistance(first, last) / 3
+		*result = append(*result,
+			vAdd(first, Scale(tHat1, dist)),
+			vAdd(last, Scale(tHat2, dist)),
+			last)
+		return
+	}
+
+	u := chordLengthParameterize(points)
+	curve := generateBezier(points, u, tHat1, tHat2)
+	maxError, splitPoint := computeMaxError(points, curve, u)
+	if maxError < errorSq {
+		*result = append(*result, curve[1], curve[2], curve[3])
+		return
+	}
+
+	// If the error is not too large, try reparameterizing the samples onto
+	// the curve and fitting again
+	if maxError < errorSq*4 {
+		for i := 0; i < maxFitIterations; i++ {
+			u = reparameterize(points, u, curve)
+			curve = generateBezier(points, u, tHat1, tHat2)
+			maxError, splitPoint = computeMaxError(points, curve, u)
+			if maxError < errorSq {
+				*result = append(*result, curve[1], curve[2], curve[3])
+				return
+			}
+		}
+	}
+
+	// Fitting failed: split at the point of maximum error and fit each half
+	tHatCenter := centerTangent(points, splitPoint)
+	fitCubic(points[:splitPoint+1], tHat1, tHatCenter, errorSq, result)
+	fitCubic(points[splitPoint:], Scale(tHatCenter, -1), tHat2, errorSq, result)
+}
+
+// centerTangent estimates the unit tangent at an interior sample.
+func centerTangent(points []Point, center int) Point {
+	t := Normalize(vSub(points[center-1], points[center+1]))
+	if math.IsNaN(t.X) || math.IsNaN(t.Y) {
+		// The neighbours coincide, so fall back to the perpendicular of the
+		// incoming chord
+		in := vSub(points[center], points[center-1])
+		t = Normalize(Point{X: -in.Y, Y: in.X})
+	}
+	return t
+}
+
+// generateBezier uses least-squares to find the handle lengths of the cubic
+// that best fits points at the parameters u, given fixed end tangents.
+func generateBezier(points []Point, u []float64, tHat1, tHat2 Point) []Point {
+	first, last := points[0], points[len(points)-1]
+
+	var c00, c01, c11, x0, x1 float64
+	for i, p := range points {
+		t, mt := u[i], 1-u[i]
+		b0 := mt * mt * mt
+		b1 := 3 * t * mt * mt
+		b2 := 3 * t * t * mt
+		b3 := t * t * t
+
+		a0 := Scale(tHat1, b1)
+		a1 := Scale(tHat2, b2)
+		c00 += vDot(a0, a0)
+		c01 += vDot(a0, a1)
+		c11 += vDot(a1, a1)
+
+		tmp := vSub(p, vAdd(Scale(first, b0+b1), Scale(last, b2+b3)))
+		x0 += vDot(a0, tmp)
+		x1 += vDot(a1, tmp)
+	}
+
+	// Compute the determinants of C and X
+	detC0C1 := c00*c11 - c01*c01
+	detC0X := c00*x1 - c01*x0
+	detXC1 := x0*c11 - x1*c01
+
+	var alphaL, alphaR float64
+	if detC0C1 != 0 {
+		alphaL = detXC1 / detC0C1
+		alphaR = detC0X / detC0C1
+	}
+
+	// If the handle lengths are degenerate (or negative, which would produce
+	// a loop), fall back to the heuristic used for two points
+	segLength := vDistance(first, last)
+	epsilon := 1e-6 * segLength
+	if alphaL < epsilon || alphaR < epsilon {
+		alphaL = segLength / 3
+		alphaR = alphaL
+	}
+
+	return []Point{
+		first,
+		vAdd(first, Scale(tHat1, alphaL)),
+		vAdd(last, Scale(tHat2, alphaR)),
+		last,
+	}
+}
+
+// chordLengthParameterize assigns each sample a parameter in [0, 1]
+// proportional to its distance along the polyline.
+func chordLengthParameterize(points []Point) []float64 {
+	u := make([]float64, len(points))
+	for i := 1; i < len(points); i++ {
+		u[i] = u[i-1] + vDistance(points[i-1], points[i])
+	}
+	total := u[len(u)-1]
+	for i := range u {
+		u[i] /= total
+	}
+	return u
+}
+
+// reparameterize moves each parameter closer to the point on the curve nearest
+// to its sample using a Newton-Raphson step.
+func reparameterize(points []Point, u []float64, curve []Point) []float64 {
+	b, _ := NewBezier(false, curve...)
+	uPrime := make([]float64, len(u))
+	for i, p := range points {
+		uPrime[i] = newtonRaphsonRootFind(b, p, u[i])
+	}
+	return uPrime
+}
+
+// newtonRaphsonRootFind improves the parameter u of the point on b closest
+// to p by minimizing (Q(u) - p) · Q'(u).
+func newtonRaphsonRootFind(b *Bezier, p Point, u float64) float64 {
+	diff := vSub(b.Get(u), p)
+	d1 := b.derivative(u)
+	d2 := compute(u, b.dpoints[1], false)
+
+	numerator := vDot(diff, d1)
+	denominator := vDot(d1, d1) + vDot(diff, d2)
+	if denominator == 0 {
+		return u
+	}
+	return u - numerator/denominator
+}
+
+// computeMaxError returns the largest squared distance between a sample and
+// its parameterized point on the curve, along with the index of that sample.
+func computeMaxError(points []Point, curve []Point, u []float64) (float64, int) {
+	splitPoint := len(points) / 2
+	maxDist := 0.0
+	for i := 1; i < len(points)-1; i++ {
+		p := compute(u[i], curve, false)
+		v := vSub(p, points[i])
+		dist := vDot(v, v)
+		if dist >= maxDist {
+			maxDist = dist
+			splitPoint = i
+		}
+	}
+	return maxDist, splitPoint
+}
@@ -0,0 +1,168 @@
+package bezier
+
+import (
+	"math"
+	"testing"
+)
+
+// sampleSpline returns n samples evenly spread over the parameters of each
+// curve of a spline.
+func sampleSpline(t *testing.T, spline []Point, n int) []Point {
+	t.Helper()
+	curves, err := Segments(spline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var samples []Point
+	for i, curve := range curves {
+		for j := 0; j < n; j++ {
+			if i > 0 && j == 0 {
+				continue
+			}
+			p := curve.Get(float64(j) / float64(n-1))
+			samples = append(samples, Point{X: p.X, Y: p.Y})
+		}
+	}
+	return samples
+}
+
+// flattenSpline returns a polyline within tolerance of a spline.
+func flattenSpline(t *testing.T, spline []Point, tolerance float64) []Point {
+	t.Helper()
+	curves, err := Segments(spline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	polyline := []Point{spline[0]}
+	for _, curve := range curves {
+		polyline = append(polyline, curve.Flatten(tolerance)[1:]...)
+	}
+	return polyline
+}
+
+func TestFitCurveTolerance(t *testing.T) {
+	hobby, err := CreateHobbySpline([]Point{{X: 0, Y: 0}, {X: 100, Y: 80}, {X: 200, Y: -40}, {X: 260, Y: 90}, {X: 380, Y: 0}}, 0.75)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wave []Point
+	for i := 0; i <= 400; i++ {
+		x := float64(i)
+		wave = append(wave, Point{X: x, Y: 30 * math.Sin(x/25)})
+	}
+	corner := []Point{}
+	for i := 0; i <= 100; i++ {
+		corner = append(corner, Point{X: float64(i), Y: 0})
+	}
+	for i := 1; i <= 100; i++ {
+		corner = append(corner, Point{X: 100, Y: float64(i)})
+	}
+
+	tests := []struct {
+		name    string
+		samples []Point
+	}{
+		{name: "hobby", samples: sampleSpline(t, hobby, 50)},
+		{name: "wave", samples: wave},
+		{name: "corner", samples: corner},
+	}
+	for _, tc := range tests {
+		for _, tolerance := range []float64{4, 1, 0.1} {
+			fitted, err := FitCurve(tc.samples, tolerance, math.Pi/4)
+			if err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+			if (len(fitted)-1)%3 != 0 {
+				t.Fatalf("%s: fitted %d points, which isn't 3n - 2", tc.name, len(fitted))
+			}
+			if n := (len(fitted) - 1) / 3; n >= len(tc.samples)/2 {
+				t.Errorf("%s at %g: fitted %d curves to %d samples", tc.name, tolerance, n, len(tc.samples))
+			}
+			first, last := tc.samples[0], tc.samples[len(tc.samples)-1]
+			if vDistance(fitted[0], first) != 0 || vDistance(fitted[len(fitted)-1], last) != 0 {
+				t.Fatalf("%s: fit runs from %v to %v, want %v to %v", tc.name, fitted[0], fitted[len(fitted)-1], first, last)
+			}
+
+			const flatness = 1e-3
+			polyline := flattenSpline(t, fitted, flatness)
+			worst := 0.0
+			for _, p := range tc.samples {
+				worst = math.Max(worst, polylineDistance(p, polyline))
+			}
+			if worst > tolerance+flatness {
+				t.Errorf("%s: a sample is %g from the fit, want at most %g", tc.name, worst, tolerance)
+			}
+		}
+	}
+}
+
+func TestFitCurveCorner(t *testing.T) {
+	var samples []Point
+	for i := 0; i <= 50; i++ {
+		samples = append(samples, Point{X: float64(i), Y: 0})
+	}
+	for i := 1; i <= 50; i++ {
+		samples = append(samples, Point{X: 50, Y: float64(i)})
+	}
+	fitted, err := FitCurve(samples, 0.5, math.Pi/4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	corner := Point{X: 50, Y: 0}
+	found := false
+	for i := 0; i < len(fitted); i += 3 {
+		if vDistance(fitted[i], corner) == 0 {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("no knot at the corner of %v", fitted)
+	}
+}
+
+func TestFitCurveFewSamples(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []Point
+		// want is the fitted spline, or nil if fitting should fail
+		want []Point
+	}{
+		{name: "none"},
+		{name: "one", samples: []Point{{X: 1, Y: 2}}},
+		{name: "one repeated", samples: []Point{{X: 1, Y: 2}, {X: 1, Y: 2}, {X: 1, Y: 2}}},
+		{
+			name:    "two",
+			samples: []Point{{X: 0, Y: 0}, {X: 30, Y: 0}},
+			want:    []Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 20, Y: 0}, {X: 30, Y: 0}},
+		},
+		{
+			name:    "two with repeats",
+			samples: []Point{{X: 0, Y: 0}, {X: 0, Y: 0}, {X: 0, Y: 30}, {X: 0, Y: 30}},
+			want:    []Point{{X: 0, Y: 0}, {X: 0, Y: 10}, {X: 0, Y: 20}, {X: 0, Y: 30}},
+		},
+	}
+	for _, tc := range tests {
+		fitted, err := FitCurve(tc.samples, 1, math.Pi/4)
+		if tc.want == nil {
+			if err == nil {
+				t.Errorf("%s: fitted %v, want an error", tc.name, fitted)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if len(fitted) != len(tc.want) {
+			t.Fatalf("%s: fitted %v, want %v", tc.name, fitted, tc.want)
+		}
+		for i := range fitted {
+			if vDistance(fitted[i], tc.want[i]) > 1e-9 {
+				t.Fatalf("%s: fitted %v, want %v", tc.name, fitted, tc.want)
+			}
+		}
+	}
+
+	if _, err := FitCurve([]Point{{X: 0, Y: 0}, {X: 1, Y: 1}}, 0, 0); err == nil {
+		t.Error("fitted with a tolerance of 0")
+	}
+}
@@ -0,0 +1,136 @@
+package bezier
+
+import "math"
+
+// Frame is an orthonormal coordinate frame attached to a point on a curve.
+type Frame struct {
+	Origin   Point
+	Tangent  Point
+	Normal   Point
+	Binormal Point
+}
+
+// Frenet returns the Frenet frame of the curve at t: the unit tangent, the
+// principal normal pointing towards the centre of curvature, and the binormal
+// completing the right-handed frame.
+//
+// Where the curve is straight the principal normal is undefined, in which
+// case an arbitrary normal perpendicular to the tangent is used.
+func (b *Bezier) Frenet(t float64) Frame {
+	tangent := b.tangent(t)
+	dd := compute(t, b.dpoints[1], b.threeDimensional)
+
+	binormal := vCross(tangent, dd)
+	if l := binormal.Length3(); l > 1e-12*dd.Length3() && l != 0 {
+		binormal = Scale3(binormal, 1/l)
+	} else {
+		binormal = vCross(tangent, perpendicular(tangent))
+	}
+
+	return Frame{
+		Origin:   b.Get(t),
+		Tangent:  tangent,
+		Normal:   vCross(binormal, tangent),
+		Binormal: binormal,
+	}
+}
+
+// Torsion returns the torsion of the curve at t, i.e. how fast it twists out
+// of its osculating plane. Planar curves have zero torsion everywhere.
+//
+//	        (r' × r") · r‴
+//	τ(t) = ----------------
+//	         |r' × r"|²
+func (b *Bezier) Torsion(t float64) float64 {
+	// Quadratics have no third derivative, and so are always planar
+	if len(b.dpoints) < 3 {
+		return 0
+	}
+	d := compute(t, b.dpoints[0], b.threeDimensional)
+	dd := compute(t, b.dpoints[1], b.threeDimensional)
+	ddd := compute(t, b.dpoints[2], b.threeDimensional)
+
+	c := vCross(d, dd)
+	l := vDot3(c, c)
+	if l == 0 {
+		return 0
+	}
+	return vDot3(c, ddd) / l
+}
+
+// RotationMinimizingFrames samples the curve at steps+1 evenly spaced values
+// of t and returns a frame at each that rotates as little as possible around
+// the tangent from one sample to the next. Unlike the Frenet frame, these
+// frames don't flip at inflections or spin on straight sections, which makes
+// them suitable for sweeping geometry along the curve.
+//
+// The first frame is the Frenet frame at t = 0, and the rest are propagated
+// using the double reflection method of Wang et al., "Computation of Rotation
+// Minimizing Frames" (2008).
+func (b *Bezier) RotationMinimizingFrames(steps int) []Frame {
+	if steps < 1 {
+		steps = 1
+	}
+	frames := []Frame{b.Frenet(0)}
+	for i := 1; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		frames = append(frames, nextFrame(frames[i-1], b.Get(t), b.tangent(t)))
+	}
+	return frames
+}
+
+// nextFrame carries the normal of prev over to a new origin and tangent using
+// two reflections: one through the plane bisecting the two origins, and one
+// that aligns the reflected tangent with the new tangent.
+func nextFrame(prev Frame, origin Point, tangent Point) Frame {
+	normal := prev.Normal
+
+	v1 := vSub3(origin, prev.Origin)
+	if c1 := vDot3(v1, v1); c1 != 0 {
+		rL := vSub3(normal, Scale3(v1, 2/c1*vDot3(v1, normal)))
+		tL := vSub3(prev.Tangent, Scale3(v1, 2/c1*vDot3(v1, prev.Tangent)))
+
+		v2 := vSub3(tangent, tL)
+		normal = rL
+		if c2 := vDot3(v2, v2); c2 != 0 {
+			normal = vSub3(rL, Scale3(v2, 2/c2*vDot3(v2, rL)))
+		}
+	}
+
+	return Frame{
+		Origin:   origin,
+		Tangent:  tangent,
+		Normal:   normal,
+		Binormal: vCross(tangent, normal),
+	}
+}
+
+// tangent returns the unit tangent at t. Where the first derivative vanishes
+// (e.g. a handle coincides with its knot) the direction is taken from the next
+// higher derivative instead.
+func (b *Bezier) tangent(t float64) Point {
+	for k, level := range b.dpoints {
+		d := compute(t, level, b.threeDimensional)
+		if l := d.Length3(); l != 0 {
+			// Approaching the end of the curve, odd higher derivatives
+			// point backwards along it
+			if t > 0.5 && k%2 == 1 {
+				l = -l
+			}
+			return Scale3(d, 1/l)
+		}
+	}
+	return Point{X: 1}
+}
+
+// perpendicular returns an arbitrary unit vector perpendicular to v.
+func perpendicular(v Point) Point {
+	// Cross with whichever axis is least aligned with v
+	axis := Point{X: 1}
+	if math.Abs(v.Y) < math.Abs(v.X) && math.Abs(v.Y) <= math.Abs(v.Z) {
+		axis = Point{Y: 1}
+	} else if math.Abs(v.Z) < math.Abs(v.X) {
+		axis = Point{Z: 1}
+	}
+	return Normalize3(vCross(v, axis))
+}
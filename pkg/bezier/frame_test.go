@@ -0,0 +1,138 @@
+package bezier
+
+import (
+	"math"
+	"testing"
+)
+
+// helix returns cubic Béziers following the helix (a cos t, a sin t, b t) for
+// t from 0 to 2π, as a spline in the 3n - 2 layout. Each piece interpolates
+// the helix and its derivative at both ends.
+func helix(a, b float64, pieces int) []Point {
+	at := func(t float64) Point { return Point{X: a * math.Cos(t), Y: a * math.Sin(t), Z: b * t} }
+	d := func(t float64) Point { return Point{X: -a * math.Sin(t), Y: a * math.Cos(t), Z: b} }
+
+	h := 2 * math.Pi / float64(pieces)
+	spline := []Point{at(0)}
+	for i := 0; i < pieces; i++ {
+		t0, t1 := float64(i)*h, float64(i+1)*h
+		spline = append(spline,
+			vAdd3(at(t0), Scale3(d(t0), h/3)),
+			vSub3(at(t1), Scale3(d(t1), h/3)),
+			at(t1),
+		)
+	}
+	return spline
+}
+
+// checkOrthonormal fails the test unless the frame's axes are unit length,
+// perpendicular to each other and right-handed.
+func checkOrthonormal(t *testing.T, f Frame, where string) {
+	t.Helper()
+	const tolerance = 1e-9
+	for _, axis := range []Point{f.Tangent, f.Normal, f.Binormal} {
+		if math.Abs(axis.Length3()-1) > tolerance {
+			t.Fatalf("%s: axis %v isn't unit length", where, axis)
+		}
+	}
+	if math.Abs(vDot3(f.Tangent, f.Normal)) > tolerance ||
+		math.Abs(vDot3(f.Tangent, f.Binormal)) > tolerance ||
+		math.Abs(vDot3(f.Normal, f.Binormal)) > tolerance {
+		t.Fatalf("%s: axes of %+v aren't perpendicular", where, f)
+	}
+	if vSub3(vCross(f.Tangent, f.Normal), f.Binormal).Length3() > tolerance {
+		t.Fatalf("%s: frame %+v isn't right-handed", where, f)
+	}
+}
+
+var helices = []struct {
+	a, b float64
+}{
+	{a: 1, b: 0.5},
+	{a: 2, b: 1},
+	{a: 1, b: 2},
+	{a: 3, b: 0.25},
+}
+
+func TestHelixCurvatureAndTorsion(t *testing.T) {
+	for _, tc := range helices {
+		curves, err := Segments3D(helix(tc.a, tc.b, 32))
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantK := tc.a / (tc.a*tc.a + tc.b*tc.b)
+		wantTau := tc.b / (tc.a*tc.a + tc.b*tc.b)
+		for i, curve := range curves {
+			for _, u := range []float64{0, 0.25, 0.5, 0.75, 1} {
+				if k := curve.Curvature(u).K; math.Abs(k-wantK) > 0.01*wantK {
+					t.Errorf("a=%g b=%g: curvature of piece %d at %g is %g, want %g", tc.a, tc.b, i, u, k, wantK)
+				}
+				if tau := curve.Torsion(u); math.Abs(tau-wantTau) > 0.01*wantTau {
+					t.Errorf("a=%g b=%g: torsion of piece %d at %g is %g, want %g", tc.a, tc.b, i, u, tau, wantTau)
+				}
+			}
+		}
+	}
+}
+
+func TestHelixFrenetFrames(t *testing.T) {
+	for _, tc := range helices {
+		curves, err := Segments3D(helix(tc.a, tc.b, 32))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, curve := range curves {
+			for _, u := range []float64{0, 0.5, 1} {
+				f := curve.Frenet(u)
+				checkOrthonormal(t, f, "Frenet frame")
+
+				// The principal normal of a helix points straight at its axis
+				angle := math.Atan2(f.Origin.Y, f.Origin.X)
+				want := Point{X: -math.Cos(angle), Y: -math.Sin(angle)}
+				if d := vSub3(f.Normal, want).Length3(); d > 0.01 {
+					t.Errorf("a=%g b=%g: normal of piece %d at %g is %v, want %v", tc.a, tc.b, i, u, f.Normal, want)
+				}
+			}
+		}
+	}
+}
+
+func TestHelixRotationMinimizingFrames(t *testing.T) {
+	for _, tc := range helices {
+		spline := helix(tc.a, tc.b, 32)
+		frames, err := SplineFrames(spline, 0.01)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, f := range frames {
+			checkOrthonormal(t, f, "rotation-minimizing frame")
+			if i == 0 {
+				continue
+			}
+			// Rotation-minimizing frames don't spin around the tangent,
+			// so the normal barely moves towards the last binormal
+			prev := frames[i-1]
+			twist := math.Atan2(vDot3(f.Normal, prev.Binormal), vDot3(f.Normal, prev.Normal))
+			if math.Abs(twist) > 1e-3 {
+				t.Fatalf("a=%g b=%g: frame %d twists by %g around the tangent", tc.a, tc.b, i, twist)
+			}
+		}
+
+		// The Frenet frame turns around the tangent at the rate of the
+		// torsion, so over the whole sweep they fall behind it by the total
+		// torsion
+		curves, err := Segments3D(spline)
+		if err != nil {
+			t.Fatal(err)
+		}
+		last := frames[len(frames)-1]
+		frenet := curves[len(curves)-1].Frenet(1)
+		got := math.Atan2(vDot3(last.Normal, frenet.Binormal), vDot3(last.Normal, frenet.Normal))
+		length := 2 * math.Pi * math.Hypot(tc.a, tc.b)
+		want := math.Remainder(-tc.b/(tc.a*tc.a+tc.b*tc.b)*length, 2*math.Pi)
+		if d := math.Abs(math.Remainder(got-want, 2*math.Pi)); d > 0.01 {
+			t.Errorf("a=%g b=%g: frames end %g radians from the Frenet frame, want %g", tc.a, tc.b, got, want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package bezier
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// GCodeUnits are the units G-code coordinates are given in.
+type GCodeUnits int
+
+const (
+	// Millimeters selects G21.
+	Millimeters GCodeUnits = iota
+	// Inches selects G20.
+	Inches
+)
+
+// GCodeOptions controls the output of WriteGCode.
+type GCodeOptions struct {
+	// Tolerance is the furthest the toolpath may stray from the curve, in
+	// output units.
+	Tolerance float64
+	// Arcs uses G2 and G3 arc moves from a biarc approximation of the curve,
+	// instead of G1 line moves from flattening it.
+	Arcs bool
+
+	Units GCodeUnits
+	// Scale converts spline coordinates to output units. Zero means 1.
+	Scale float64
+	// Height flips the Y axis when non-zero, so that it points up as on
+	// most machines, with the origin at the bottom left of a canvas Height
+	// spline units tall.
+	Height float64
+
+	// FeedRate is the speed of drawing moves, in output units per minute.
+	// Zero leaves it to the machine.
+	FeedRate float64
+	// PenUp and PenDown are written before travelling to the start of a
+	// spline and before drawing it, e.g. "M5" and "M3 S1000" for a laser,
+	// or "G0 Z5" and "G1 Z-1" for a router. Multiple commands can be
+	// separated by newlines. Empty commands are omitted.
+	PenUp   string
+	PenDown string
+}
+
+// WriteGCode writes a program that draws each of the splines in turn,
+// lifting the pen to travel between them.
+func WriteGCode(w io.Writer, splines [][]Point, opts GCodeOptions) error {
+	if opts.Tolerance <= 0 {
+		return errors.New("tolerance must be positive")
+	}
+
+	scale := orDefault(opts.Scale, 1)
+	var paths [][]*Bezier
+	for _, spline := range splines {
+		// Work in output coordinates, so that tolerances and arc
+		// directions are as the machine sees them
+		moved := make([]Point, len(spline))
+		for i, p := range spline {
+			if opts.Height != 0 {
+				p.Y = opts.Height - p.Y
+			}
+			moved[i] = Point{X: p.X * scale, Y: p.Y * scale}
+		}
+		curves, err := Segments(moved)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, curves)
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("; hobby-spline\n")
+	if opts.Units == Inches {
+		bw.WriteString("G20\n")
+	} else {
+		bw.WriteString("G21\n")
+	}
+	// Absolute coordinates, and arc centers relative to the start of arcs
+	bw.WriteString("G90\n")
+	bw.WriteString("G91.1\n")
+	writeGCodeCommand(bw, opts.PenUp)
+
+	for _, curves := range paths {
+		start := curves[0].Points[0]
+		fmt.Fprintf(bw, "G0 X%s Y%s\n", gcodeNumber(start.X), gcodeNumber(start.Y))
+		writeGCodeCommand(bw, opts.PenDown)
+
+		feed := ""
+		if opts.FeedRate > 0 {
+			feed = " F" + gcodeNumber(opts.FeedRate)
+		}
+		for _, curve := range curves {
+			if opts.Arcs {
+				for _, a := range curve.Biarcs(opts.Tolerance) {
+					writeGCodeArc(bw, a, feed)
+					feed = ""
+				}
+				continue
+			}
+			points := curve.Flatten(opts.Tolerance)
+			for _, p := range points[1:] {
+				fmt.Fprintf(bw, "G1 X%s Y%s%s\n", gcodeNumber(p.X), gcodeNumber(p.Y), feed)
+				feed = ""
+			}
+		}
+
+		writeGCodeCommand(bw, opts.PenUp)
+	}
+
+	bw.WriteString("M2\n")
+	return bw.Flush()
+}
+
+// writeGCodeArc writes a single arc move, or a line move if the arc is
+// straight.
+func writeGCodeArc(w *bufio.Writer, a Arc, feed string) {
+	if vDistance(a.Start, a.End) == 0 {
+		return
+	}
+	if a.IsLine() {
+		fmt.Fprintf(w, "G1 X%s Y%s%s\n", gcodeNumber(a.End.X), gcodeNumber(a.End.Y), feed)
+		return
+	}
+	command := "G2"
+	if a.CounterClockwise {
+		command = "G3"
+	}
+	offset := vSub(a.Center, a.Start)
+	fmt.Fprintf(w, "%s X%s Y%s I%s J%s%s\n", command,
+		gcodeNumber(a.End.X), gcodeNumber(a.End.Y), gcodeNumber(offset.X), gcodeNumber(offset.Y), feed)
+}
+
+// writeGCodeCommand writes user supplied commands, one per line.
+func writeGCodeCommand(w *bufio.Writer, command string) {
+	for _, line := range strings.Split(command, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			w.WriteString(line + "\n")
+		}
+	}
+}
+
+// gcodeNumber formats a number with up to 4 decimals, enough for a tenth of
+// a thousandth of an inch, and without trailing zeros.
+func gcodeNumber(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 4, 64)
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,183 @@
+package bezier
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// gcodeSlack allows for the rounding of coordinates to four decimals.
+const gcodeSlack = 1e-3
+
+// parseToolpath reads the moves of a G-code program written by WriteGCode
+// back as polylines, one per G0 travel move, with arcs sampled finely.
+func parseToolpath(t *testing.T, program []byte) [][]Point {
+	t.Helper()
+	var paths [][]Point
+	var at Point
+	sc := bufio.NewScanner(bytes.NewReader(program))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], ";") {
+			continue
+		}
+		args := map[byte]float64{}
+		for _, f := range fields[1:] {
+			v, err := strconv.ParseFloat(f[1:], 64)
+			if err != nil {
+				t.Fatalf("line %q: %v", sc.Text(), err)
+			}
+			args[f[0]] = v
+		}
+		to := Point{X: args['X'], Y: args['Y']}
+
+		switch fields[0] {
+		case "G0":
+			paths = append(paths, []Point{to})
+		case "G1":
+			paths[len(paths)-1] = append(paths[len(paths)-1], to)
+		case "G2", "G3":
+			center := vAdd(at, Point{X: args['I'], Y: args['J']})
+			from := math.Atan2(at.Y-center.Y, at.X-center.X)
+			sweep := math.Atan2(to.Y-center.Y, to.X-center.X) - from
+			if fields[0] == "G3" && sweep <= 0 {
+				sweep += 2 * math.Pi
+			} else if fields[0] == "G2" && sweep >= 0 {
+				sweep -= 2 * math.Pi
+			}
+			radius := vDistance(at, center)
+			for i := 1; i <= 64; i++ {
+				angle := from + sweep*float64(i)/64
+				p := Point{X: center.X + radius*math.Cos(angle), Y: center.Y + radius*math.Sin(angle)}
+				paths[len(paths)-1] = append(paths[len(paths)-1], p)
+			}
+		case "G20", "G21", "G90", "G91.1", "M2":
+			continue
+		default:
+			t.Fatalf("unexpected command %q", sc.Text())
+		}
+		if fields[0] != "G0" || len(paths) > 0 {
+			at = to
+		}
+	}
+	return paths
+}
+
+// polylineDistance returns the distance from p to the nearest point of a
+// polyline.
+func polylineDistance(p Point, polyline []Point) float64 {
+	best := math.Inf(1)
+	for i := 1; i < len(polyline); i++ {
+		a, b := polyline[i-1], polyline[i]
+		ab := vSub(b, a)
+		t := 0.0
+		if l := vDot(ab, ab); l > 0 {
+			t = math.Max(0, math.Min(1, vDot(vSub(p, a), ab)/l))
+		}
+		best = math.Min(best, vDistance(p, vAdd(a, Scale(ab, t))))
+	}
+	return best
+}
+
+func TestWriteGCodeTolerance(t *testing.T) {
+	hobby, err := CreateHobbySpline([]Point{{X: 0, Y: 0}, {X: 30, Y: 40}, {X: 60, Y: -10}, {X: 90, Y: 30}}, 0.75)
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed, err := CreateClosedHobbySpline([]Point{{X: 0, Y: 0}, {X: 50, Y: 0}, {X: 50, Y: 50}, {X: 0, Y: 50}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	splines := map[string][]Point{
+		"hobby":  hobby,
+		"closed": closed,
+		// An S bend, which has to be split at its inflection
+		"inflection": {{X: 0, Y: 0}, {X: 40, Y: 60}, {X: 60, Y: -60}, {X: 100, Y: 0}},
+		// A cusp at t = 0.4, where no biarc fits however finely the curve
+		// is split
+		"cusp": {{X: 0, Y: 0}, {X: 100, Y: 100}, {X: -12.5, Y: 37.5}, {X: 100, Y: 0}},
+		// A straight line
+		"line": {{X: 0, Y: 0}, {X: 10, Y: 10}, {X: 20, Y: 20}, {X: 30, Y: 30}},
+	}
+
+	for name, spline := range splines {
+		for _, arcs := range []bool{false, true} {
+			for _, tolerance := range []float64{1, 0.1, 0.01} {
+				var buf bytes.Buffer
+				err := WriteGCode(&buf, [][]Point{spline}, GCodeOptions{Tolerance: tolerance, Arcs: arcs})
+				if err != nil {
+					t.Fatal(err)
+				}
+				checkToolpath(t, fmt.Sprintf("%s, arcs %v", name, arcs), buf.Bytes(), spline, tolerance)
+			}
+		}
+	}
+
+}
+
+// checkToolpath fails the test unless the program draws spline, and strays
+// no further than tolerance from it.
+func checkToolpath(t *testing.T, name string, program []byte, spline []Point, tolerance float64) {
+	t.Helper()
+	paths := parseToolpath(t, program)
+	if len(paths) != 1 {
+		t.Fatalf("%s: %d paths, want 1", name, len(paths))
+	}
+	toolpath := paths[0]
+
+	curves, err := Segments(spline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var curve []Point
+	for _, c := range curves {
+		for i := 0; i <= 1000; i++ {
+			curve = append(curve, c.Get(float64(i)/1000))
+		}
+	}
+
+	// Both ways, so that the toolpath neither strays from the curve nor cuts
+	// any of it short
+	worst := 0.0
+	for _, p := range toolpath {
+		worst = math.Max(worst, polylineDistance(p, curve))
+	}
+	for _, p := range curve {
+		worst = math.Max(worst, polylineDistance(p, toolpath))
+	}
+	if worst > tolerance+gcodeSlack {
+		t.Errorf("%s: toolpath strays %g from the curve, more than the tolerance of %g", name, worst, tolerance)
+	}
+}
+
+func TestWriteGCodeOptions(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteGCode(&buf, [][]Point{
+		{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 1}, {X: 3, Y: 0}},
+		{{X: 10, Y: 0}, {X: 11, Y: 1}, {X: 12, Y: 1}, {X: 13, Y: 0}},
+	}, GCodeOptions{
+		Tolerance: 0.1,
+		Units:     Inches,
+		Scale:     2,
+		Height:    5,
+		FeedRate:  1200,
+		PenUp:     "M5",
+		PenDown:   "M3 S1000\nG4 P0.1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	program := buf.String()
+	for _, want := range []string{"G20\n", "M5\nG0 X0 Y10\nM3 S1000\nG4 P0.1\nG1 ", " F1200\n", "M5\nG0 X20 Y10\nM3", "M5\nM2\n"} {
+		if !strings.Contains(program, want) {
+			t.Errorf("program lacks %q:\n%s", want, program)
+		}
+	}
+	if strings.Count(program, "F1200") != 2 {
+		t.Errorf("feed rate should be set once per path:\n%s", program)
+	}
+}
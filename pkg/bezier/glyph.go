@@ -0,0 +1,104 @@
+package bezier
+
+import (
+	"fmt"
+
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// Contour is one closed outline of a glyph.
+type Contour struct {
+	// Segments are quadratic Bézier curves for TrueType fonts, and cubics
+	// for CFF (PostScript flavoured OpenType) fonts. Lines are converted to
+	// cubics.
+	Segments []*Bezier
+}
+
+// OnCurvePoints returns the distinct end points of the contour's segments,
+// i.e. the points the outline passes through, without repeating the first.
+func (c Contour) OnCurvePoints() []Point {
+	return onCurvePoints(c.Segments, true)
+}
+
+// Spline returns the contour as a single closed spline in the 3n + 1 layout
+// of CreateClosedHobbySpline, raising quadratic segments to cubics.
+func (c Contour) Spline() []Point {
+	return joinSegments(c.Segments)
+}
+
+// Refit returns a closed Hobby spline through the on-curve points of the
+// contour, for comparison with the designed outline.
+func (c Contour) Refit() ([]Point, error) {
+	return CreateClosedHobbySpline(c.OnCurvePoints())
+}
+
+// LoadGlyph returns the outline of the glyph for r in f, scaled so that an em
+// is ppem units. Like on screen, the origin is where the glyph sits on the
+// baseline, and Y points down.
+func LoadGlyph(f *sfnt.Font, r rune, ppem float64) ([]Contour, error) {
+	var buf sfnt.Buffer
+	index, err := f.GlyphIndex(&buf, r)
+	if err != nil {
+		return nil, err
+	}
+	if index == 0 {
+		return nil, fmt.Errorf("font has no glyph for %q", r)
+	}
+	segments, err := f.LoadGlyph(&buf, index, fixed.Int26_6(ppem*64), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var contours []Contour
+	var start, cur Point
+	// closeContour joins the current contour back to its start, if the font
+	// didn't already
+	closeContour := func() {
+		if len(contours) == 0 {
+			return
+		}
+		c := &contours[len(contours)-1]
+		if vDistance(cur, start) > 0 {
+			c.Segments = append(c.Segments, lineSegment(cur, start))
+		}
+		if len(c.Segments) == 0 {
+			contours = contours[:len(contours)-1]
+		}
+	}
+
+	for _, seg := range segments {
+		switch seg.Op {
+		case sfnt.SegmentOpMoveTo:
+			closeContour()
+			contours = append(contours, Contour{})
+			start = glyphPoint(seg.Args[0])
+			cur = start
+			continue
+		case sfnt.SegmentOpLineTo:
+			end := glyphPoint(seg.Args[0])
+			// Fonts often repeat points, e.g. to close a contour explicitly
+			if vDistance(cur, end) == 0 {
+				continue
+			}
+			contours[len(contours)-1].Segments = append(contours[len(contours)-1].Segments, lineSegment(cur, end))
+			cur = end
+		case sfnt.SegmentOpQuadTo:
+			curve, _ := NewBezier(false, cur, glyphPoint(seg.Args[0]), glyphPoint(seg.Args[1]))
+			contours[len(contours)-1].Segments = append(contours[len(contours)-1].Segments, curve)
+			cur = curve.Points[2]
+		case sfnt.SegmentOpCubeTo:
+			curve, _ := NewBezier(false, cur, glyphPoint(seg.Args[0]), glyphPoint(seg.Args[1]), glyphPoint(seg.Args[2]))
+			contours[len(contours)-1].Segments = append(contours[len(contours)-1].Segments, curve)
+			cur = curve.Points[3]
+		}
+	}
+	closeContour()
+
+	return contours, nil
+}
+
+// glyphPoint converts a point from a glyph outline.
+func glyphPoint(p fixed.Point26_6) Point {
+	return Point{X: float64(p.X) / 64, Y: float64(p.Y) / 64}
+}
@@ -0,0 +1,96 @@
+package bezier
+
+import (
+	"math"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+)
+
+func TestLoadGlyphRefit(t *testing.T) {
+	f, err := sfnt.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const ppem = 1000
+
+	tests := []struct {
+		r        rune
+		contours int
+		// near is how far the refit may stray from the designed outline.
+		// Hobby's curves round off corners, so that is only checked for
+		// round glyphs.
+		near float64
+	}{
+		{r: 'O', contours: 2, near: 10},
+		{r: 'o', contours: 2, near: 10},
+		{r: 'I', contours: 1},
+		{r: 'B', contours: 3},
+	}
+	for _, tc := range tests {
+		contours, err := LoadGlyph(f, tc.r, ppem)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.r, err)
+		}
+		if len(contours) != tc.contours {
+			t.Fatalf("%q: %d contours, want %d", tc.r, len(contours), tc.contours)
+		}
+		for i, c := range contours {
+			// The segments join up into a loop
+			segs := c.Segments
+			for j, seg := range segs {
+				next := segs[(j+1)%len(segs)]
+				if end := seg.Points[len(seg.Points)-1]; vDistance(end, next.Points[0]) != 0 {
+					t.Fatalf("%q: segment %d of contour %d ends at %v, but the next starts at %v", tc.r, j, i, end, next.Points[0])
+				}
+			}
+			// Glyphs sit on the baseline, overshooting it a little where
+			// they're round, and Y points down
+			for _, seg := range segs {
+				for _, p := range seg.Points {
+					if p.Y > 0.05*ppem || p.Y < -ppem {
+						t.Fatalf("%q: contour %d reaches %v, outside the em above the baseline", tc.r, i, p)
+					}
+				}
+			}
+
+			knots := c.OnCurvePoints()
+			if len(knots) != len(segs) {
+				t.Errorf("%q: contour %d has %d on-curve points for %d segments", tc.r, i, len(knots), len(segs))
+			}
+			spline := c.Spline()
+			if len(spline) != 3*len(segs)+1 || vDistance(spline[0], spline[len(spline)-1]) != 0 {
+				t.Fatalf("%q: contour %d is a spline of %d points, want a loop of %d", tc.r, i, len(spline), 3*len(segs)+1)
+			}
+
+			refit, err := c.Refit()
+			if err != nil {
+				t.Fatalf("%q: contour %d: %v", tc.r, i, err)
+			}
+			if len(refit) != len(spline) {
+				t.Fatalf("%q: contour %d refits to %d points, want %d", tc.r, i, len(refit), len(spline))
+			}
+			for j, k := range knots {
+				if vDistance(refit[3*j], k) != 0 {
+					t.Fatalf("%q: knot %d of contour %d refits at %v, want %v", tc.r, j, i, refit[3*j], k)
+				}
+			}
+			if tc.near == 0 {
+				continue
+			}
+			outline := flattenSpline(t, spline, 0.1)
+			worst := 0.0
+			for _, p := range sampleSpline(t, refit, 10) {
+				worst = math.Max(worst, polylineDistance(p, outline))
+			}
+			if worst > tc.near {
+				t.Errorf("%q: contour %d refits up to %g from the outline, want at most %g", tc.r, i, worst, tc.near)
+			}
+		}
+	}
+
+	if _, err := LoadGlyph(f, '￿', ppem); err == nil {
+		t.Error("loaded a glyph the font doesn't have")
+	}
+}
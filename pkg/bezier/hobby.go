@@ -43,6 +43,269 @@ func CreateHobbySpline(points []Point, omega float64) ([]Point, error) {
 		gamma[i] = vAngleBetween(chords[i-1], chords[i])
 	}
 
+	// Solve for the alpha angles for each point (these are the angles
+	// between each chord[i] and the vector c0[i] - P[i], i.e. the vector
+	// from knot i to the subsequent control point, which is tangent to the
+	// curve at P[i]), and beta (like alpha, but for the chord and handle
+	// vector arriving at P[i+1] rather than leaving from P[i]).
+	alpha, beta := openHobbyAngles(d, gamma, omega)
+
+	// Now that we have the angles between the handle vector and the chord
+	// both arriving at and leaving from each point, we can solve for the
+	// positions of the handle (control) points themselves.
+	c0 := make([]Point, n)
+	c1 := make([]Point, n)
+	for i := 0; i < n; i++ {
+		// Compute the magnitudes of the handle vectors at this point.
+		// (Jackowski, formula 22)
+		a := (rho(alpha[i], beta[i]) * d[i]) / 3
+		b := (rho(beta[i], alpha[i]) * d[i]) / 3
+
+		// Use the magnitudes, and the chords and turning angles, to find
+		// the positions of the control points in the global coordinate space.
+		c0[i] = vAdd(points[i], Scale(Normalize(Rotate(chords[i], alpha[i])), a))
+		c1[i] = vSub(points[i+1], Scale(Normalize(Rotate(chords[i], -1*beta[i])), b))
+	}
+
+	// Finally, gather up and return the spline points (both knots and
+	// control points) as a single ordered list of [x, y] pairs.
+	var result []Point
+	for i := 0; i < n; i++ {
+		result = append(result, points[i], c0[i], c1[i])
+	}
+	result = append(result, points[n])
+
+	return result, nil
+}
+
+// CreateHobbySpline3D is the space-curve counterpart of CreateHobbySpline.
+// It uses the Z coordinates of the points and returns a spline in the same
+// 3n - 2 layout.
+//
+// In three dimensions there is no single plane to measure turning angles in.
+// Instead, the turning angle at each knot is measured in the osculating plane
+// of the two chords meeting there, and the tangent at the knot is chosen
+// within that plane. The plane normals are oriented consistently along the
+// path, so a planar set of points produces the same curve as
+// CreateHobbySpline.
+func CreateHobbySpline3D(points []Point, omega float64) ([]Point, error) {
+	if len(points) < 2 {
+		return nil, errors.New("not enough points")
+	}
+
+	n := len(points) - 1
+
+	chords := make([]Point, n)
+	d := make([]float64, n)
+	for i := 0; i < n; i++ {
+		chords[i] = vSub3(points[i+1], points[i])
+		d[i] = chords[i].Length3()
+		if d[i] == 0 {
+			return nil, errors.New("zero-length chord")
+		}
+	}
+
+	// axis[i] is the normal of the osculating plane at P[i], the axis the
+	// chords turn around there. It is undefined where successive chords are
+	// collinear, in which case the neighbouring axis is borrowed.
+	axis := make([]Point, n+1)
+	valid := make([]bool, n+1)
+	gamma := make([]float64, n+1)
+	for i := 1; i < n; i++ {
+		c := vCross(chords[i-1], chords[i])
+		if l := c.Length3(); l > 1e-12*d[i-1]*d[i] {
+			axis[i] = Scale3(c, 1/l)
+			valid[i] = true
+		}
+		gamma[i] = math.Atan2(c.Length3(), vDot3(chords[i-1], chords[i]))
+	}
+
+	// Orient every axis the same way as the one before it, flipping the
+	// sign of the turning angle to match. This is what lets the angles
+	// change sign along the path, just like in the planar case.
+	prev := -1
+	for i := 1; i < n; i++ {
+		if !valid[i] {
+			continue
+		}
+		if prev >= 0 && vDot3(axis[i], axis[prev]) < 0 {
+			axis[i] = Scale3(axis[i], -1)
+			gamma[i] = -gamma[i]
+		}
+		prev = i
+	}
+
+	// Fill in the undefined axes, including both ends of the path, from
+	// their defined neighbours. If the points are collinear every turning
+	// angle is zero, and the axis doesn't matter.
+	fallback := Point{Z: 1}
+	for i := 1; i < n; i++ {
+		if valid[i] {
+			fallback = axis[i]
+			break
+		}
+	}
+	axis[0] = fallback
+	for i := 1; i <= n; i++ {
+		if !valid[i] {
+			axis[i] = axis[i-1]
+		}
+	}
+
+	// With the turning angles known, the angles of the handles are exactly
+	// the planar ones
+	alpha, beta := openHobbyAngles(d, gamma, omega)
+
+	// Leaving P[i] the handle is rotated within the plane at P[i], and
+	// arriving at P[i+1] within the plane at P[i+1]
+	var result []Point
+	for i := 0; i < n; i++ {
+		a := (rho(alpha[i], beta[i]) * d[i]) / 3
+		b := (rho(beta[i], alpha[i]) * d[i]) / 3
+
+		unit := Scale3(chords[i], 1/d[i])
+		c0 := vAdd3(points[i], Scale3(RotateAround(unit, axis[i], alpha[i]), a))
+		c1 := vSub3(points[i+1], Scale3(RotateAround(unit, axis[i+1], -1*beta[i]), b))
+		result = append(result, points[i], c0, c1)
+	}
+	result = append(result, points[n])
+
+	return result, nil
+}
+
+// CreateClosedHobbySpline is like CreateHobbySpline, but joins the last point
+// back to the first with another segment so the curve forms a smooth loop.
+// There are no ends, so there is no omega.
+//
+// The output has 3n + 1 points: the segment from the last point back to the
+// first is included, and the spline finishes by repeating the first point.
+func CreateClosedHobbySpline(points []Point) ([]Point, error) {
+	if len(points) < 3 {
+		return nil, errors.New("not enough points")
+	}
+
+	// Here there are n points, and also n chords since the last one wraps
+	// around to P[0]
+	n := len(points)
+
+	chords := make([]Point, n)
+	d := make([]float64, n)
+	for i := 0; i < n; i++ {
+		chords[i] = vSub(points[(i+1)%n], points[i])
+		d[i] = chords[i].Length()
+		if d[i] == 0 {
+			return nil, errors.New("zero-length chord")
+		}
+	}
+
+	// Every point has a turning angle, including P[0]
+	gamma := make([]float64, n)
+	for i := 0; i < n; i++ {
+		gamma[i] = vAngleBetween(chords[(i+n-1)%n], chords[i])
+	}
+
+	// Every equation is the interior one from the open case, with indices
+	// wrapping around, and every tension is 1
+	tension := make([]float64, n)
+	for i := range tension {
+		tension[i] = 1
+	}
+	alpha := cyclicAngles(d, gamma, tension, tension)
+
+	var result []Point
+	for i := 0; i < n; i++ {
+		beta := -1*gamma[(i+1)%n] - alpha[(i+1)%n]
+		a := (rho(alpha[i], beta) * d[i]) / 3
+		b := (rho(beta, alpha[i]) * d[i]) / 3
+
+		c0 := vAdd(points[i], Scale(Normalize(Rotate(chords[i], alpha[i])), a))
+		c1 := vSub(points[(i+1)%n], Scale(Normalize(Rotate(chords[i], -1*beta)), b))
+		result = append(result, points[i], c0, c1)
+	}
+	result = append(result, points[0])
+
+	return result, nil
+}
+
+// KnotAngles describes how a spline passes through one of its knots, in the
+// terms of Jackowski's paper used by CreateHobbySpline. Angles are in
+// radians, and those that don't exist at the ends of an open spline are NaN.
+type KnotAngles struct {
+	// Alpha is the angle from the chord leaving the knot to the handle
+	// leaving it.
+	Alpha float64
+	// Beta is the angle from the handle arriving at the knot to the chord
+	// arriving at it.
+	Beta float64
+	// Gamma is the turning angle of the chords at the knot.
+	Gamma float64
+
+	// InLength and OutLength are the distances from the knot to the handles
+	// arriving at and leaving it.
+	InLength  float64
+	OutLength float64
+}
+
+// MeasureKnots measures a spline in the 3n - 2 layout of CreateHobbySpline at
+// each of its knots. If closed is set, the spline is one returned by
+// CreateClosedHobbySpline, ending where it starts, and its last knot isn't
+// measured again.
+func MeasureKnots(spline []Point, closed bool) []KnotAngles {
+	if len(spline) < 4 {
+		return nil
+	}
+	segments := (len(spline) - 1) / 3
+	n := segments + 1
+	if closed {
+		n = segments
+	}
+
+	chord := func(i int) Point {
+		return vSub(spline[3*i+3], spline[3*i])
+	}
+
+	result := make([]KnotAngles, n)
+	for i := range result {
+		k := &result[i]
+		knot := spline[3*i]
+		k.Alpha, k.Beta, k.Gamma = math.NaN(), math.NaN(), math.NaN()
+
+		in, out := i-1, i
+		if closed && in < 0 {
+			in = segments - 1
+		}
+		if in >= 0 {
+			handle := spline[3*in+2]
+			k.Beta = -1 * vAngleBetween(chord(in), vSub(spline[3*in+3], handle))
+			k.InLength = vDistance(knot, handle)
+		}
+		if out < segments {
+			handle := spline[3*out+1]
+			k.Alpha = vAngleBetween(chord(out), vSub(handle, knot))
+			k.OutLength = vDistance(knot, handle)
+		}
+		if in >= 0 && out < segments {
+			k.Gamma = vAngleBetween(chord(in), chord(out))
+		}
+	}
+	return result
+}
+
+// openHobbyAngles solves for the angles between the chords of an open spline
+// and its handles, given the lengths d of its n chords and the turning
+// angles gamma at its n + 1 knots, where gamma[0] and gamma[n] are zero.
+// alpha[i] is the angle the handle leaving knot i makes with the chord
+// leaving it, and beta[i] the angle the chord arriving at knot i + 1 makes
+// with the handle arriving there.
+func openHobbyAngles(d, gamma []float64, omega float64) (alpha, beta []float64) {
+	n := len(d)
+
+	// A single chord is a straight line. This has to be handled up front,
+	// since with an omega of 1 its equations are degenerate.
+	if n == 1 {
+		return make([]float64, 2), make([]float64, 1)
+	}
+
 	// Set up the system of linear equations (Jackowski, formula 38).
 	// We're representing this system as a tridiagonal matrix, because
 	// we can solve such a system in O(n) time using the Thomas algorithm.
@@ -60,59 +323,62 @@ func CreateHobbySpline(points []Point, omega float64) ([]Point, error) {
 	D[0] = -1 * C[0] * gamma[1]
 
 	for i := 1; i < n; i++ {
-		A[i] = 1 / d[i-1]
-		B[i] = (2*d[i-1] + 2*d[i]) / (d[i-1] * d[i])
-		C[i] = 1 / d[i]
-		D[i] = (-1 * (2*gamma[i]*d[i] + gamma[i+1]*d[i-1])) / (d[i-1] * d[i])
+		A[i], B[i], C[i], D[i] = curvatureEquation(d[i-1], d[i], gamma[i], gamma[i+1], 1, 1, 1, 1)
 	}
 
 	A[n] = 2*omega + 1
 	B[n] = 2 + omega
 	D[n] = 0
 
-	// Solve the tridiagonal matrix of equations using the Thomas algorithm,
-	// yielding the alpha angles for each point (these are the angles between
-	// each chord[i] and the vector c0[i] - P[i], i.e. the vector from knot i
-	// to the subsequent control point, which is tangent to the curve at P[i]).
-	alpha := thomas(A, B, C, D)
-
-	// Use alpha (the chord angle) and gamma (the turning angle of the chord
-	// polyline) to solve for beta at each point (beta is like alpha, but for
-	// the chord and handle vector arriving at P[i] rather than leaving from it).
-	beta := make([]float64, n)
+	alpha = thomas(A, B, C, D)
 
-	for i := 0; i < n-1; i++ {
+	// With gamma[n] zero, beta follows from alpha and gamma the same way
+	// at every knot
+	beta = make([]float64, n)
+	for i := 0; i < n; i++ {
 		beta[i] = -1*gamma[i+1] - alpha[i+1]
 	}
+	return alpha, beta
+}
 
-	beta[n-1] = -1 * alpha[n]
-
-	// Now that we have the angles between the handle vector and the chord
-	// both arriving at and leaving from each point, we can solve for the
-	// positions of the handle (control) points themselves.
-	c0 := make([]Point, n)
-	c1 := make([]Point, n)
-	for i := 0; i < n; i++ {
-		// Compute the magnitudes of the handle vectors at this point.
-		// (Jackowski, formula 22)
-		a := (rho(alpha[i], beta[i]) * d[i]) / 3
-		b := (rho(beta[i], alpha[i]) * d[i]) / 3
-
-		// Use the magnitudes, and the chords and turning angles, to find
-		// the positions of the control points in the global coordinate space.
-		c0[i] = vAdd(points[i], Scale(Normalize(Rotate(chords[i], alpha[i])), a))
-		c1[i] = vSub(points[i+1], Scale(Normalize(Rotate(chords[i], -1*beta[i])), b))
-	}
+// curvatureEquation returns the coefficients of the equation that makes the
+// mock curvature continuous at an interior knot:
+//
+//	a*x[i-1] + b*x[i] + c*x[i+1] = rhs
+//
+// where x[i] is the angle between the chord leaving knot i and the handle
+// leaving it. dPrev and d are the lengths of the chords arriving at and
+// leaving the knot, and psi and psiNext the turning angles at it and at the
+// next knot. alphaPrev and beta are the reciprocal tensions at the start and
+// end of the segment arriving at the knot, and alpha and betaNext those of
+// the segment leaving it (Knuth, METAFONT: The Program, §274ff). With every
+// tension 1, this is Jackowski's formula 38.
+func curvatureEquation(dPrev, d, psi, psiNext, alphaPrev, beta, alpha, betaNext float64) (a, b, c, rhs float64) {
+	arriving := beta * beta * dPrev
+	leaving := alpha * alpha * d
+	in, out := (3-alphaPrev)/arriving, (3-betaNext)/leaving
+	a, b, c = alphaPrev/arriving, in+out, betaNext/leaving
+	rhs = -1*in*psi - c*psiNext
+	return a, b, c, rhs
+}
 
-	// Finally, gather up and return the spline points (both knots and
-	// control points) as a single ordered list of [x, y] pairs.
-	var result []Point
+// cyclicAngles solves for the angles x[i] between each chord of a cycle and
+// the handle leaving its start, given the lengths d of the n chords, the
+// turning angles psi at the n knots, and the reciprocal tensions alpha[i]
+// leaving and beta[i] arriving at each knot. Every knot has an interior
+// equation, with indices wrapping around, so the system is solved with
+// cyclicThomas.
+func cyclicAngles(d, psi, alpha, beta []float64) []float64 {
+	n := len(d)
+	A := make([]float64, n)
+	B := make([]float64, n)
+	C := make([]float64, n)
+	D := make([]float64, n)
 	for i := 0; i < n; i++ {
-		result = append(result, points[i], c0[i], c1[i])
+		prev, next := (i+n-1)%n, (i+1)%n
+		A[i], B[i], C[i], D[i] = curvatureEquation(d[prev], d[i], psi[i], psi[next], alpha[prev], beta[i], alpha[i], beta[next])
 	}
-	result = append(result, points[n])
-
-	return result, nil
+	return cyclicThomas(A, B, C, D)
 }
 
 func thomas(A, B, C, D []float64) []float64 {
@@ -153,6 +419,42 @@ func thomas(A, B, C, D []float64) []float64 {
 	return X
 }
 
+// cyclicThomas solves a tridiagonal system whose corners are also set: A[0]
+// is the coefficient of X[n] in the first equation, and C[n] the coefficient
+// of X[0] in the last. It uses the Sherman-Morrison formula to reduce the
+// problem to two ordinary tridiagonal systems.
+func cyclicThomas(A, B, C, D []float64) []float64 {
+	n := len(B) - 1
+	if n < 2 {
+		// Only two equations; the corners are ordinary coefficients
+		Bp := []float64{B[0], B[1]}
+		det := Bp[0]*Bp[1] - (A[0]+C[0])*(A[1]+C[1])
+		return []float64{
+			(D[0]*Bp[1] - (A[0]+C[0])*D[1]) / det,
+			(Bp[0]*D[1] - (A[1]+C[1])*D[0]) / det,
+		}
+	}
+
+	gamma := -1 * B[0]
+	Bp := make([]float64, n+1)
+	copy(Bp, B)
+	Bp[0] -= gamma
+	Bp[n] -= A[0] * C[n] / gamma
+
+	u := make([]float64, n+1)
+	u[0] = gamma
+	u[n] = C[n]
+
+	x := thomas(A, Bp, C, D)
+	z := thomas(A, Bp, C, u)
+
+	fact := (x[0] + A[0]*x[n]/gamma) / (1 + z[0] + A[0]*z[n]/gamma)
+	for i := range x {
+		x[i] -= fact * z[i]
+	}
+	return x
+}
+
 // Rho is the 'velocity function' that computes the length of the handles for
 // the Bézier spline.
 //
@@ -0,0 +1,183 @@
+package bezier
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMeasureKnotsClosed(t *testing.T) {
+	square := []Point{{X: 1, Y: 0}, {X: 0, Y: 1}, {X: -1, Y: 0}, {X: 0, Y: -1}}
+	spline, err := CreateClosedHobbySpline(square)
+	if err != nil {
+		t.Fatal(err)
+	}
+	measures := MeasureKnots(spline, true)
+	if len(measures) != len(square) {
+		t.Fatalf("measured %d knots, want %d", len(measures), len(square))
+	}
+	// Every knot of a symmetric closed curve is the same, including the
+	// first, which the curve arrives at from the last. Going counterclockwise,
+	// the handles are halfway between the chords, clockwise from the one
+	// leaving the knot.
+	for i, m := range measures {
+		if math.Abs(m.Gamma-math.Pi/2) > 1e-9 {
+			t.Errorf("knot %d turns by %g, want π/2", i, m.Gamma)
+		}
+		if math.Abs(m.Alpha+math.Pi/4) > 1e-9 || math.Abs(m.Beta+math.Pi/4) > 1e-9 {
+			t.Errorf("knot %d has alpha %g and beta %g, want -π/4", i, m.Alpha, m.Beta)
+		}
+		if math.Abs(m.InLength-m.OutLength) > 1e-9 {
+			t.Errorf("knot %d has handles %g and %g long, want them equal", i, m.InLength, m.OutLength)
+		}
+	}
+}
+
+func TestClosedHobbySplineSeam(t *testing.T) {
+	square := []Point{{X: 10, Y: 10}, {X: 110, Y: 10}, {X: 110, Y: 110}, {X: 10, Y: 110}}
+	spline, err := CreateClosedHobbySpline(square)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spline) != 3*len(square)+1 {
+		t.Fatalf("%d points, want %d", len(spline), 3*len(square)+1)
+	}
+
+	// The handles either side of the first knot, where the last segment
+	// joins the first, are in line and equally long
+	in, out := vSub(spline[0], spline[len(spline)-2]), vSub(spline[1], spline[0])
+	if math.Abs(in.X*out.Y-in.Y*out.X) > 1e-9*in.Length()*out.Length() || in.X*out.X+in.Y*out.Y <= 0 {
+		t.Errorf("curve turns at the seam, arriving along %v and leaving along %v", in, out)
+	}
+	if math.Abs(in.Length()-out.Length()) > 1e-9 {
+		t.Errorf("handles at the seam are %g and %g long", in.Length(), out.Length())
+	}
+
+	// Every segment is the one before it turned a quarter about the center
+	center := Point{X: 60, Y: 60}
+	for i := 3; i < len(spline); i++ {
+		p := vSub(spline[i-3], center)
+		want := vAdd(center, Point{X: -p.Y, Y: p.X})
+		if vDistance(spline[i], want) > 1e-9 {
+			t.Errorf("point %d is %v, want %v", i, spline[i], want)
+		}
+	}
+
+	// Starting at another knot doesn't move the seam's curve
+	points := []Point{{X: 0, Y: 0}, {X: 50, Y: -20}, {X: 90, Y: 30}, {X: 60, Y: 80}, {X: 10, Y: 60}}
+	want, err := CreateClosedHobbySpline(points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for shift := 1; shift < len(points); shift++ {
+		got, err := CreateClosedHobbySpline(append(points[shift:len(points):len(points)], points[:shift]...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range got {
+			if j := (i + 3*shift) % (len(want) - 1); vDistance(got[i], want[j]) > 1e-9 {
+				t.Fatalf("starting at knot %d, point %d is %v, want %v", shift, i, got[i], want[j])
+			}
+		}
+	}
+}
+
+func TestMeasureKnotsOpenEndingAtStart(t *testing.T) {
+	// An open curve can end where it starts without being closed
+	points := []Point{{X: 0, Y: 0}, {X: 10, Y: 10}, {X: 20, Y: 0}, {X: 0, Y: 0}}
+	spline, err := CreateHobbySpline(points, 0.75)
+	if err != nil {
+		t.Fatal(err)
+	}
+	measures := MeasureKnots(spline, false)
+	if len(measures) != len(points) {
+		t.Fatalf("measured %d knots, want %d", len(measures), len(points))
+	}
+	first, last := measures[0], measures[len(measures)-1]
+	if !math.IsNaN(first.Beta) || !math.IsNaN(first.Gamma) || first.InLength != 0 {
+		t.Errorf("first knot is measured as arrived at: %+v", first)
+	}
+	if !math.IsNaN(last.Alpha) || !math.IsNaN(last.Gamma) || last.OutLength != 0 {
+		t.Errorf("last knot is measured as left: %+v", last)
+	}
+}
+
+func TestHobbySpline3DPlanar(t *testing.T) {
+	tests := [][]Point{
+		{{X: 0, Y: 0}, {X: 10, Y: 10}, {X: 20, Y: 0}},
+		{{X: 0, Y: 0}, {X: 100, Y: 80}, {X: 200, Y: -40}, {X: 260, Y: 90}, {X: 380, Y: 0}},
+		// Clockwise first, so the planes' normals point down the Z axis
+		{{X: 0, Y: 0}, {X: 10, Y: -10}, {X: 20, Y: 0}, {X: 30, Y: 0}, {X: 40, Y: 20}},
+		// Collinear knots have no plane at all
+		{{X: 0, Y: 0}, {X: 10, Y: 5}, {X: 20, Y: 10}, {X: 25, Y: 30}},
+		{{X: 0, Y: 0}, {X: 1, Y: 1}},
+	}
+	for _, points := range tests {
+		for _, omega := range []float64{0, 0.75, 1} {
+			want, err := CreateHobbySpline(points, omega)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := CreateHobbySpline3D(points, omega)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("%v: got %d points, want %d", points, len(got), len(want))
+			}
+			// The rotations are computed differently, so only agree to
+			// within rounding
+			for i := range got {
+				if vSub3(got[i], want[i]).Length3() > 1e-9 || got[i].Z != 0 {
+					t.Fatalf("%v with omega %g: point %d is %v, want %v", points, omega, i, got[i], want[i])
+				}
+			}
+		}
+	}
+}
+
+func TestHobbySpline3DHelix(t *testing.T) {
+	// Knots every eighth of a turn along the helix (a cos t, a sin t, b t)
+	const a, b = 10.0, 4.0
+	var points []Point
+	for i := 0; i <= 24; i++ {
+		u := float64(i) * math.Pi / 4
+		points = append(points, Point{X: a * math.Cos(u), Y: a * math.Sin(u), Z: b * u})
+	}
+	spline, err := CreateHobbySpline3D(points, 0.75)
+	if err != nil {
+		t.Fatal(err)
+	}
+	curves, err := Segments3D(spline)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, curve := range curves {
+		if vSub3(curve.Get(0), points[i]).Length3() != 0 || vSub3(curve.Get(1), points[i+1]).Length3() != 0 {
+			t.Fatalf("piece %d doesn't run between knots %d and %d", i, i, i+1)
+		}
+		// The curve is smooth at every knot
+		if i > 0 {
+			in := Normalize3(vSub3(spline[3*i], spline[3*i-1]))
+			out := Normalize3(vSub3(spline[3*i+1], spline[3*i]))
+			if vSub3(in, out).Length3() > 1e-9 {
+				t.Errorf("curve turns a corner at knot %d: %v then %v", i, in, out)
+			}
+		}
+
+		// Away from the ends, which are curled, the curve stays close to
+		// the helix
+		if i < 2 || i >= len(curves)-2 {
+			continue
+		}
+		for _, u := range []float64{0.25, 0.5, 0.75} {
+			p := curve.Get(u)
+			r := math.Hypot(p.X, p.Y)
+			angle := math.Atan2(p.Y, p.X)
+			angle += 2 * math.Pi * math.Round((p.Z/b-angle)/(2*math.Pi))
+			if math.Abs(r-a) > 0.01*a || math.Abs(p.Z-b*angle) > 0.01*a {
+				t.Errorf("piece %d at %g is at %v, %g off the helix's radius and %g off its pitch", i, u, p, r-a, p.Z-b*angle)
+			}
+		}
+	}
+}
@@ -0,0 +1,529 @@
+package bezier
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseMetaPost parses a path written in MetaPost syntax, e.g.
+//
+//	z0..z1..tension 1.2..z2{dir 90}..cycle
+//
+// Points are either literal pairs like (3,-4.5) or names looked up in vars.
+// The supported subset of MetaPost covers the joins .., ..., -- and ---,
+// "tension" (with "atleast", "infinity" and "and"), "controls" (with "and"),
+// direction specifiers ({dir 90}, {curl 2}, {(1,2)}, {z1}, {up}, {down},
+// {left} and {right}) and a closing "cycle". Angles are in degrees,
+// counter-clockwise from the X axis.
+//
+// The path is returned unsolved; call Solve to get its control points.
+func ParseMetaPost(src string, vars map[string]Point) (*Path, error) {
+	p := &mpParser{vars: vars}
+	if err := p.tokenize(src); err != nil {
+		return nil, err
+	}
+	return p.parsePath()
+}
+
+// mpJoin holds the constraints a join places on the knot after it, until
+// that knot is parsed.
+type mpJoin struct {
+	tension float64
+	control *Point
+	curl    bool
+}
+
+func (j mpJoin) apply(k *Knot) {
+	k.LeftTension = j.tension
+	if j.control != nil {
+		k.LeftType, k.LeftControl = JoinExplicit, *j.control
+	} else if j.curl && k.LeftType == JoinOpen {
+		k.LeftType, k.LeftCurl = JoinCurl, 1
+	}
+}
+
+type mpToken struct {
+	// kind is "num", "name", "eof" or the punctuation itself
+	kind string
+	text string
+	pos  int
+}
+
+type mpParser struct {
+	tokens []mpToken
+	pos    int
+	vars   map[string]Point
+}
+
+// mpJoins are the joins made of dots and dashes, longest first.
+var mpJoins = []string{"...", "..", "---", "--"}
+
+func (p *mpParser) tokenize(src string) error {
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+			continue
+		case strings.ContainsRune("(),{}&", c):
+			p.tokens = append(p.tokens, mpToken{kind: string(c), text: string(c), pos: i})
+			i++
+			continue
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(src) && (unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i])) || src[i] == '_' || src[i] == '\'') {
+				i++
+			}
+			p.tokens = append(p.tokens, mpToken{kind: "name", text: src[start:i], pos: start})
+			continue
+		}
+
+		join := ""
+		for _, j := range mpJoins {
+			if strings.HasPrefix(src[i:], j) {
+				join = j
+				break
+			}
+		}
+		if join != "" {
+			p.tokens = append(p.tokens, mpToken{kind: join, text: join, pos: i})
+			i += len(join)
+			continue
+		}
+
+		if c == '-' || c == '+' || c == '.' || unicode.IsDigit(c) {
+			start := i
+			i++
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.') {
+				// A second dot starts a join, e.g. "1..2"
+				if src[i] == '.' && i+1 < len(src) && src[i+1] == '.' {
+					break
+				}
+				i++
+			}
+			p.tokens = append(p.tokens, mpToken{kind: "num", text: src[start:i], pos: start})
+			continue
+		}
+
+		return fmt.Errorf("metapost: offset %d: unexpected %q", i, c)
+	}
+	return nil
+}
+
+func (p *mpParser) peek() mpToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	end := 0
+	if len(p.tokens) > 0 {
+		last := p.tokens[len(p.tokens)-1]
+		end = last.pos + len(last.text)
+	}
+	return mpToken{kind: "eof", pos: end}
+}
+
+// accept consumes the next token if it is of the given kind, or a name with
+// the given text.
+func (p *mpParser) accept(kind string) bool {
+	t := p.peek()
+	if t.kind == kind || (t.kind == "name" && t.text == kind) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *mpParser) expect(kind string) error {
+	if !p.accept(kind) {
+		return p.errorf("expected %q", kind)
+	}
+	return nil
+}
+
+func (p *mpParser) errorf(format string, args ...any) error {
+	t := p.peek()
+	found := t.text
+	if t.kind == "eof" {
+		found = "end of input"
+	}
+	return fmt.Errorf("metapost: offset %d: %s, found %q", t.pos, fmt.Sprintf(format, args...), found)
+}
+
+func (p *mpParser) parsePath() (*Path, error) {
+	path := &Path{}
+	var pending *mpJoin
+	for {
+		// A direction before the point applies to its left side...
+		var left *Knot
+		if p.peek().kind == "{" {
+			left = &Knot{}
+			if err := p.parseDirection(&left.LeftType, &left.LeftAngle, &left.LeftCurl); err != nil {
+				return nil, err
+			}
+		}
+
+		if pending != nil && p.accept("cycle") {
+			first := &path.Knots[0]
+			pending.apply(first)
+			if left != nil {
+				first.LeftType, first.LeftAngle, first.LeftCurl = left.LeftType, left.LeftAngle, left.LeftCurl
+			}
+			path.Cycle = true
+			break
+		}
+
+		point, err := p.parsePoint()
+		if err != nil {
+			return nil, err
+		}
+		knot := Knot{Point: point}
+		if pending != nil {
+			pending.apply(&knot)
+		}
+		if left != nil {
+			knot.LeftType, knot.LeftAngle, knot.LeftCurl = left.LeftType, left.LeftAngle, left.LeftCurl
+		}
+
+		// ...and a direction after it to its right side
+		if p.peek().kind == "{" {
+			if err := p.parseDirection(&knot.RightType, &knot.RightAngle, &knot.RightCurl); err != nil {
+				return nil, err
+			}
+		}
+		path.Knots = append(path.Knots, knot)
+
+		if p.peek().kind == "eof" {
+			break
+		}
+		if pending, err = p.parseJoin(&path.Knots[len(path.Knots)-1]); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek().kind != "eof" {
+		return nil, p.errorf("expected end of path")
+	}
+	if len(path.Knots) < 2 {
+		return nil, p.errorf("a path needs at least 2 points")
+	}
+	return path, nil
+}
+
+// parseJoin parses a join, recording its constraints on the knot before it
+// and returning those on the knot after it.
+func (p *mpParser) parseJoin(k *Knot) (*mpJoin, error) {
+	j := &mpJoin{}
+	switch t := p.peek(); t.kind {
+	case "..":
+		p.pos++
+		if p.accept("tension") {
+			var err error
+			if k.RightTension, err = p.parseTension(); err != nil {
+				return nil, err
+			}
+			j.tension = k.RightTension
+			if p.accept("and") {
+				if j.tension, err = p.parseTension(); err != nil {
+					return nil, err
+				}
+			}
+			if err := p.expect(".."); err != nil {
+				return nil, err
+			}
+		} else if p.accept("controls") {
+			c0, err := p.parsePoint()
+			if err != nil {
+				return nil, err
+			}
+			c1 := c0
+			if p.accept("and") {
+				if c1, err = p.parsePoint(); err != nil {
+					return nil, err
+				}
+			}
+			k.RightType, k.RightControl = JoinExplicit, c0
+			j.control = &c1
+			if err := p.expect(".."); err != nil {
+				return nil, err
+			}
+		}
+	case "...":
+		p.pos++
+		k.RightTension, j.tension = -1, -1
+	case "--":
+		p.pos++
+		if k.RightType == JoinOpen {
+			k.RightType, k.RightCurl = JoinCurl, 1
+		}
+		j.curl = true
+	case "---":
+		p.pos++
+		k.RightTension, j.tension = infinityTension, infinityTension
+	case "&":
+		return nil, p.errorf("concatenation is not supported")
+	default:
+		return nil, p.errorf("expected a join")
+	}
+	return j, nil
+}
+
+// parseTension parses a tension value, returning "atleast" tensions as
+// negative numbers.
+func (p *mpParser) parseTension() (float64, error) {
+	atLeast := p.accept("atleast")
+	var tension float64
+	if p.accept("infinity") {
+		tension = infinityTension
+	} else {
+		var err error
+		if tension, err = p.parseNumber(); err != nil {
+			return 0, err
+		}
+		if tension < 0.75 {
+			return 0, fmt.Errorf("metapost: tension must be at least 0.75, got %v", tension)
+		}
+	}
+	if atLeast {
+		tension = -tension
+	}
+	return tension, nil
+}
+
+// parseDirection parses a direction specifier in braces into one side of a
+// knot.
+func (p *mpParser) parseDirection(typ *JoinType, angle *float64, curl *float64) error {
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	switch {
+	case p.accept("curl"):
+		c, err := p.parseNumber()
+		if err != nil {
+			return err
+		}
+		if c < 0 {
+			return fmt.Errorf("metapost: curl must not be negative, got %v", c)
+		}
+		*typ, *curl = JoinCurl, c
+	case p.accept("dir"):
+		deg, err := p.parseNumber()
+		if err != nil {
+			return err
+		}
+		*typ, *angle = JoinGiven, deg*math.Pi/180
+	case p.accept("right"):
+		*typ, *angle = JoinGiven, 0
+	case p.accept("up"):
+		*typ, *angle = JoinGiven, math.Pi/2
+	case p.accept("left"):
+		*typ, *angle = JoinGiven, math.Pi
+	case p.accept("down"):
+		*typ, *angle = JoinGiven, -math.Pi/2
+	default:
+		v, err := p.parsePoint()
+		if err != nil {
+			return err
+		}
+		if v.Length() == 0 {
+			// MetaPost treats a zero direction as curl 1
+			*typ, *curl = JoinCurl, 1
+		} else {
+			*typ, *angle = JoinGiven, v.Angle()
+		}
+	}
+	return p.expect("}")
+}
+
+// parsePoint parses a literal pair or a named point.
+func (p *mpParser) parsePoint() (Point, error) {
+	if t := p.peek(); t.kind == "name" {
+		pt, ok := p.vars[t.text]
+		if !ok {
+			return Point{}, p.errorf("unknown point %q", t.text)
+		}
+		p.pos++
+		return Point{X: pt.X, Y: pt.Y}, nil
+	}
+
+	if err := p.expect("("); err != nil {
+		return Point{}, p.errorf("expected a point")
+	}
+	x, err := p.parseNumber()
+	if err != nil {
+		return Point{}, err
+	}
+	if err := p.expect(","); err != nil {
+		return Point{}, err
+	}
+	y, err := p.parseNumber()
+	if err != nil {
+		return Point{}, err
+	}
+	if err := p.expect(")"); err != nil {
+		return Point{}, err
+	}
+	return Point{X: x, Y: y}, nil
+}
+
+func (p *mpParser) parseNumber() (float64, error) {
+	t := p.peek()
+	if t.kind != "num" {
+		return 0, p.errorf("expected a number")
+	}
+	f, err := strconv.ParseFloat(t.text, 64)
+	if err != nil {
+		return 0, p.errorf("invalid number")
+	}
+	p.pos++
+	return f, nil
+}
+
+// String formats the path in MetaPost syntax, such that ParseMetaPost reads
+// it back to the same path.
+func (p *Path) String() string {
+	var sb strings.Builder
+	n := len(p.Knots)
+	segments := n - 1
+	if p.Cycle {
+		segments = n
+	}
+
+	// straight reports whether segment k is a "--" join
+	straight := func(k int) bool {
+		if k < 0 || k >= segments {
+			return false
+		}
+		a, b := p.Knots[k], p.Knots[(k+1)%n]
+		return a.RightType == JoinCurl && a.RightCurl == 1 && b.LeftType == JoinCurl && b.LeftCurl == 1 &&
+			a.RightTension == 0 && b.LeftTension == 0
+	}
+
+	writeLeft := func(k int) {
+		knot := p.Knots[k]
+		// The left side of an open path's first knot only matters when it is
+		// copied to an open right side
+		if k == 0 && !p.Cycle && knot.RightType != JoinOpen {
+			return
+		}
+		prev := k - 1
+		if k == 0 {
+			prev = n - 1
+		}
+		if knot.LeftType != JoinExplicit && !straight(prev) {
+			sb.WriteString(mpDirection(knot.LeftType, knot.LeftAngle, knot.LeftCurl))
+		}
+	}
+
+	for k, knot := range p.Knots {
+		if k > 0 || !p.Cycle {
+			writeLeft(k)
+		}
+		sb.WriteString(mpPair(knot.Point))
+
+		// The right side only needs writing if it differs from the left,
+		// since MetaPost copies a lone direction to both sides
+		sameAsLeft := knot.RightType == knot.LeftType &&
+			(knot.RightType == JoinGiven && knot.RightAngle == knot.LeftAngle ||
+				knot.RightType == JoinCurl && knot.RightCurl == knot.LeftCurl)
+		if knot.RightType != JoinExplicit && !straight(k) && !(sameAsLeft && (k > 0 || p.Cycle)) {
+			sb.WriteString(mpDirection(knot.RightType, knot.RightAngle, knot.RightCurl))
+		}
+
+		if k == segments {
+			break
+		}
+		next := p.Knots[(k+1)%n]
+		switch {
+		case knot.RightType == JoinExplicit:
+			sb.WriteString("..controls " + mpPair(knot.RightControl) + " and " + mpPair(next.LeftControl) + "..")
+		case straight(k):
+			sb.WriteString("--")
+		default:
+			sb.WriteString(mpTension(knot.RightTension, next.LeftTension))
+		}
+	}
+
+	if p.Cycle {
+		writeLeft(0)
+		sb.WriteString("cycle")
+	}
+	return sb.String()
+}
+
+// FormatMetaPost formats a solved spline, in the 3n - 2 layout of
+// CreateHobbySpline or Path.Solve, the way MetaPost shows paths: every
+// segment with explicit control points. If cycle is set, the last knot is
+// written as "cycle".
+func FormatMetaPost(spline []Point, cycle bool) string {
+	var sb strings.Builder
+	for i := 0; i < len(spline); i += 3 {
+		if i > 0 {
+			sb.WriteString("..controls " + mpPair(spline[i-2]) + " and " + mpPair(spline[i-1]) + "..")
+		}
+		if cycle && i > 0 && i == len(spline)-1 {
+			sb.WriteString("cycle")
+		} else {
+			sb.WriteString(mpPair(spline[i]))
+		}
+	}
+	return sb.String()
+}
+
+func mpDirection(typ JoinType, angle float64, curl float64) string {
+	switch typ {
+	case JoinGiven:
+		return "{dir " + mpNumber(angle*180/math.Pi) + "}"
+	case JoinCurl:
+		return "{curl " + mpNumber(curl) + "}"
+	}
+	return ""
+}
+
+func mpTension(right float64, left float64) string {
+	if right == 0 {
+		right = 1
+	}
+	if left == 0 {
+		left = 1
+	}
+	switch {
+	case right == 1 && left == 1:
+		return ".."
+	case right == -1 && left == -1:
+		return "..."
+	case right == infinityTension && left == infinityTension:
+		return "---"
+	case right == left:
+		return "..tension " + mpTensionValue(right) + ".."
+	}
+	return "..tension " + mpTensionValue(right) + " and " + mpTensionValue(left) + ".."
+}
+
+func mpTensionValue(t float64) string {
+	prefix := ""
+	if t < 0 {
+		prefix, t = "atleast ", -t
+	}
+	if t == infinityTension {
+		return prefix + "infinity"
+	}
+	return prefix + mpNumber(t)
+}
+
+func mpPair(p Point) string {
+	return "(" + mpNumber(p.X) + "," + mpNumber(p.Y) + ")"
+}
+
+// mpNumber formats f with up to five decimals, like MetaPost does.
+func mpNumber(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 5, 64)
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
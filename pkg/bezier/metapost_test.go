@@ -0,0 +1,183 @@
+package bezier
+
+import (
+	"math"
+	"testing"
+)
+
+// The expected controls are rounded to the five decimals MetaPost shows, so
+// solved ones only need to agree to about as much.
+const mpTolerance = 1e-4
+
+func TestSolveMetaPost(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		// want is the solved spline, in the 3n - 2 layout
+		want []Point
+	}{
+		{
+			name: "circle",
+			src:  "(1,0)..(0,1)..(-1,0)..(0,-1)..cycle",
+			want: []Point{
+				{X: 1, Y: 0}, {X: 1, Y: 0.55228}, {X: 0.55228, Y: 1},
+				{X: 0, Y: 1}, {X: -0.55228, Y: 1}, {X: -1, Y: 0.55228},
+				{X: -1, Y: 0}, {X: -1, Y: -0.55228}, {X: -0.55228, Y: -1},
+				{X: 0, Y: -1}, {X: 0.55228, Y: -1}, {X: 1, Y: -0.55228},
+				{X: 1, Y: 0},
+			},
+		},
+		{
+			name: "circle scaled",
+			src:  "(10,0)..(0,10)..(-10,0)..(0,-10)..cycle",
+			want: []Point{
+				{X: 10, Y: 0}, {X: 10, Y: 5.52285}, {X: 5.52285, Y: 10},
+				{X: 0, Y: 10}, {X: -5.52285, Y: 10}, {X: -10, Y: 5.52285},
+				{X: -10, Y: 0}, {X: -10, Y: -5.52285}, {X: -5.52285, Y: -10},
+				{X: 0, Y: -10}, {X: 5.52285, Y: -10}, {X: 10, Y: -5.52285},
+				{X: 10, Y: 0},
+			},
+		},
+		{
+			name: "open",
+			src:  "(0,0)..(10,10)..(20,0)",
+			want: []Point{
+				{X: 0, Y: 0}, {X: 0, Y: 5.52285}, {X: 4.47715, Y: 10},
+				{X: 10, Y: 10}, {X: 15.52285, Y: 10}, {X: 20, Y: 5.52285},
+				{X: 20, Y: 0},
+			},
+		},
+		{
+			name: "tension",
+			src:  "(0,0)..tension 1.2..(10,10)..tension 1.2..(20,0)",
+			want: []Point{
+				{X: 0, Y: 0}, {X: 0, Y: 4.60237}, {X: 5.39763, Y: 10},
+				{X: 10, Y: 10}, {X: 14.60237, Y: 10}, {X: 20, Y: 4.60237},
+				{X: 20, Y: 0},
+			},
+		},
+		{
+			name: "direction",
+			src:  "(0,0){dir 0}..{dir 0}(10,10)",
+			want: []Point{
+				{X: 0, Y: 0}, {X: 5.52285, Y: 0}, {X: 4.47715, Y: 10},
+				{X: 10, Y: 10},
+			},
+		},
+		{
+			name: "direction up",
+			src:  "(0,0){dir 90}..(10,10)..(20,0)",
+			want: []Point{
+				{X: 0, Y: 0}, {X: 0, Y: 5.52285}, {X: 4.47715, Y: 10},
+				{X: 10, Y: 10}, {X: 15.52285, Y: 10}, {X: 20, Y: 5.52285},
+				{X: 20, Y: 0},
+			},
+		},
+		{
+			name: "curl",
+			src:  "(0,0){curl 0}..(10,10)..{curl 0}(20,0)",
+			want: []Point{
+				{X: 0, Y: 0}, {X: 2.02917, Y: 4.89885}, {X: 4.91935, Y: 10},
+				{X: 10, Y: 10}, {X: 15.08065, Y: 10}, {X: 17.97083, Y: 4.89885},
+				{X: 20, Y: 0},
+			},
+		},
+		{
+			// The controls already lie within the triangles of the chords
+			// and the directions, so "tension atleast 1" changes nothing
+			name: "atleast",
+			src:  "(0,0)...(10,10)...(20,0)",
+			want: []Point{
+				{X: 0, Y: 0}, {X: 0, Y: 5.52285}, {X: 4.47715, Y: 10},
+				{X: 10, Y: 10}, {X: 15.52285, Y: 10}, {X: 20, Y: 5.52285},
+				{X: 20, Y: 0},
+			},
+		},
+		{
+			name: "infinity",
+			src:  "(0,0)---(10,10)---(20,0)",
+			want: []Point{
+				{X: 0, Y: 0}, {X: 0, Y: 0.00135}, {X: 9.99865, Y: 10},
+				{X: 10, Y: 10}, {X: 10.00135, Y: 10}, {X: 20, Y: 0.00135},
+				{X: 20, Y: 0},
+			},
+		},
+		{
+			name: "straight",
+			src:  "(0,0)--(30,0)--(30,30)",
+			want: []Point{
+				{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 20, Y: 0},
+				{X: 30, Y: 0}, {X: 30, Y: 10}, {X: 30, Y: 20},
+				{X: 30, Y: 30},
+			},
+		},
+		{
+			name: "controls",
+			src:  "(0,0)..controls (1,2) and (3,4)..(5,0)",
+			want: []Point{
+				{X: 0, Y: 0}, {X: 1, Y: 2}, {X: 3, Y: 4},
+				{X: 5, Y: 0},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path, err := ParseMetaPost(tc.src, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := path.Solve()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("solved %d points, want %d: %s", len(got), len(tc.want), FormatMetaPost(got, path.Cycle))
+			}
+			for i := range got {
+				if math.Abs(got[i].X-tc.want[i].X) > mpTolerance || math.Abs(got[i].Y-tc.want[i].Y) > mpTolerance {
+					t.Fatalf("point %d is %s, want %s: %s", i, mpPair(got[i]), mpPair(tc.want[i]), FormatMetaPost(got, path.Cycle))
+				}
+			}
+		})
+	}
+}
+
+func TestMetaPostRoundTrip(t *testing.T) {
+	sources := []string{
+		"(1,0)..(0,1)..(-1,0)..(0,-1)..cycle",
+		"(0,0)..(10,10)..(20,0)",
+		"(0,0)..tension 1.2..(10,10)..tension 1.2 and 1.5..(20,0)",
+		"(0,0){dir 90}..(10,10)..{dir -90}(20,0)",
+		"(0,0){curl 0}..(10,10)..{curl 2}(20,0)",
+		"(0,0)...(10,10)---(20,0)--(30,10)",
+		"(0,0)..controls (1,2) and (3,4)..(5,0)..(10,5)",
+		"(0,0)..tension atleast 1.5..(10,10){dir 45}..cycle",
+	}
+	for _, src := range sources {
+		path, err := ParseMetaPost(src, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", src, err)
+		}
+		again, err := ParseMetaPost(path.String(), nil)
+		if err != nil {
+			t.Fatalf("%s: parsing %s: %v", src, path.String(), err)
+		}
+		if again.String() != path.String() {
+			t.Errorf("%s: formatted as %s, then as %s", src, path.String(), again.String())
+		}
+
+		want, err := path.Solve()
+		if err != nil {
+			t.Fatalf("%s: %v", src, err)
+		}
+		got, err := again.Solve()
+		if err != nil {
+			t.Fatalf("%s: %v", src, err)
+		}
+		if FormatMetaPost(got, again.Cycle) != FormatMetaPost(want, path.Cycle) {
+			t.Errorf("%s: solved as %s after formatting as %s, want %s",
+				src, FormatMetaPost(got, again.Cycle), path.String(), FormatMetaPost(want, path.Cycle))
+		}
+	}
+}
@@ -0,0 +1,389 @@
+package bezier
+
+import (
+	"errors"
+	"math"
+)
+
+// JoinType is the constraint on one side of a knot in a Path, following
+// MetaPost's terminology. The left side of a knot faces the segment arriving
+// at it, and the right side the segment leaving it.
+type JoinType int
+
+const (
+	// JoinOpen leaves the direction at the knot for the solver to choose.
+	JoinOpen JoinType = iota
+	// JoinCurl fixes the curl at the knot: the ratio of the curvature at the
+	// knot to the curvature at the other end of the segment. Only
+	// meaningful where the path starts or stops being smooth, e.g. at the
+	// ends of an open path.
+	JoinCurl
+	// JoinGiven fixes the direction of the path at the knot.
+	JoinGiven
+	// JoinExplicit fixes the control point next to the knot.
+	JoinExplicit
+)
+
+// Knot is a point a Path passes through, along with the constraints on the
+// segments either side of it.
+type Knot struct {
+	Point
+
+	LeftType  JoinType
+	RightType JoinType
+
+	// LeftAngle and RightAngle are the directions of JoinGiven sides, in
+	// radians counter-clockwise from the X axis.
+	LeftAngle  float64
+	RightAngle float64
+
+	// LeftCurl and RightCurl are the curls of JoinCurl sides.
+	LeftCurl  float64
+	RightCurl float64
+
+	// LeftControl and RightControl are the control points of JoinExplicit
+	// sides. If one side of a segment is explicit, so must the other be.
+	LeftControl  Point
+	RightControl Point
+
+	// LeftTension and RightTension are the tensions of the segments arriving
+	// at and leaving the knot. Zero means the default tension of 1. A
+	// negative value means "tension atleast" its absolute value, which keeps
+	// the segment within the triangle formed by its chord and end
+	// directions.
+	LeftTension  float64
+	RightTension float64
+}
+
+// Path is a sequence of knots with MetaPost-style constraints, which Solve
+// turns into a Bézier spline. A cyclic path has an extra segment joining the
+// last knot back to the first.
+type Path struct {
+	Knots []Knot
+	Cycle bool
+}
+
+// infinityTension is MetaPost's "tension infinity", which makes segments
+// practically straight.
+const infinityTension = 4095.99998
+
+// Solve chooses the control points of the path, returning a spline in the
+// 3n - 2 layout of CreateHobbySpline. A cyclic path repeats its first knot at
+// the end, so n knots produce 3n + 1 points.
+//
+// This follows MetaPost's own algorithm: the path is split into pieces at
+// knots where it isn't free to choose a direction, and each piece is solved
+// for the directions that make its mock curvature continuous. The handle
+// lengths use Hobby's velocity function, as MetaPost does. Note that this
+// means that a path without constraints produces a slightly different curve
+// than CreateHobbySpline, which uses a simpler velocity function.
+func (p *Path) Solve() ([]Point, error) {
+	n := len(p.Knots)
+	if n < 2 {
+		return nil, errors.New("not enough points")
+	}
+
+	knots := make([]Knot, n)
+	copy(knots, p.Knots)
+	segments := n - 1
+	if p.Cycle {
+		segments = n
+	}
+	next := func(k int) int { return (k + 1) % n }
+
+	// Segments between coincident knots can only be a point
+	for k := 0; k < segments; k++ {
+		q := next(k)
+		if vDistance(knots[k].Point, knots[q].Point) == 0 && knots[k].RightType != JoinExplicit {
+			knots[k].RightType, knots[k].RightControl = JoinExplicit, knots[k].Point
+			knots[q].LeftType, knots[q].LeftControl = JoinExplicit, knots[q].Point
+		}
+	}
+
+	for k := range knots {
+		if err := normalizeKnot(&knots[k]); err != nil {
+			return nil, err
+		}
+	}
+
+	// Open paths are curled at their ends unless told otherwise
+	if !p.Cycle {
+		if knots[0].RightType == JoinOpen {
+			knots[0].RightType, knots[0].RightCurl = JoinCurl, 1
+		}
+		if knots[n-1].LeftType == JoinOpen {
+			knots[n-1].LeftType, knots[n-1].LeftCurl = JoinCurl, 1
+		}
+	}
+
+	for k := 0; k < segments; k++ {
+		if (knots[k].RightType == JoinExplicit) != (knots[next(k)].LeftType == JoinExplicit) {
+			return nil, errors.New("explicit control points must come in pairs")
+		}
+	}
+
+	// Collect the breakpoints, i.e. the knots the pieces are split at
+	var breaks []int
+	for k, knot := range knots {
+		if knot.LeftType != JoinOpen || knot.RightType != JoinOpen {
+			breaks = append(breaks, k)
+		}
+	}
+
+	theta := make([]float64, n)
+	phi := make([]float64, n)
+	if len(breaks) == 0 {
+		solveCycle(knots, theta, phi)
+	} else {
+		for i, s := range breaks {
+			if !p.Cycle && i == len(breaks)-1 {
+				break
+			}
+			e := breaks[(i+1)%len(breaks)]
+			if knots[s].RightType == JoinExplicit {
+				continue
+			}
+			// Walk around from s to e, wrapping for cyclic paths
+			idx := []int{s}
+			for k := next(s); ; k = next(k) {
+				idx = append(idx, k)
+				if k == e {
+					break
+				}
+			}
+			if err := solvePiece(knots, idx, theta, phi); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var result []Point
+	for k := 0; k < segments; k++ {
+		q := next(k)
+		result = append(result, knots[k].Point)
+		if knots[k].RightType == JoinExplicit {
+			result = append(result, knots[k].RightControl, knots[q].LeftControl)
+		} else {
+			c0, c1 := controlsFor(knots[k], knots[q], theta[k], phi[q])
+			result = append(result, c0, c1)
+		}
+	}
+	result = append(result, knots[segments%n].Point)
+	for i := range result {
+		result[i] = Point{X: result[i].X, Y: result[i].Y}
+	}
+
+	return result, nil
+}
+
+// normalizeKnot resolves a knot with a constraint on one side only, so that
+// the constraint holds on both sides, the way MetaPost does.
+func normalizeKnot(k *Knot) error {
+	if k.RightType == JoinOpen {
+		switch k.LeftType {
+		case JoinGiven:
+			k.RightType, k.RightAngle = JoinGiven, k.LeftAngle
+		case JoinCurl:
+			k.RightType, k.RightCurl = JoinCurl, k.LeftCurl
+		case JoinExplicit:
+			// Continue in the direction the explicit segment arrives in
+			if d := vSub(k.Point, k.LeftControl); d.Length() != 0 {
+				k.RightType, k.RightAngle = JoinGiven, d.Angle()
+			} else {
+				k.RightType, k.RightCurl = JoinCurl, 1
+			}
+		}
+	}
+	if k.LeftType == JoinOpen {
+		switch k.RightType {
+		case JoinGiven:
+			k.LeftType, k.LeftAngle = JoinGiven, k.RightAngle
+		case JoinCurl:
+			k.LeftType, k.LeftCurl = JoinCurl, k.RightCurl
+		case JoinExplicit:
+			if d := vSub(k.RightControl, k.Point); d.Length() != 0 {
+				k.LeftType, k.LeftAngle = JoinGiven, d.Angle()
+			} else {
+				k.LeftType, k.LeftCurl = JoinCurl, 1
+			}
+		}
+	}
+	if k.LeftCurl < 0 || k.RightCurl < 0 {
+		return errors.New("curl must not be negative")
+	}
+	return nil
+}
+
+// reciprocalTension returns 1/|tension|, treating zero as the default of 1.
+func reciprocalTension(tension float64) float64 {
+	if tension == 0 {
+		return 1
+	}
+	return 1 / math.Abs(tension)
+}
+
+// solvePiece solves for the angles of the knots idx[0]...idx[m], where only
+// the first and last knots have constraints. theta[k] is the angle between the
+// chord leaving knot k and the direction the path leaves it in, and phi[k] the
+// angle between the chord arriving at knot k and the direction the path
+// arrives in.
+func solvePiece(knots []Knot, idx []int, theta, phi []float64) error {
+	m := len(idx) - 1
+
+	// A single segment curled at both ends is a straight line. This has to
+	// be handled up front, since its equations are degenerate.
+	if m == 1 && knots[idx[0]].RightType == JoinCurl && knots[idx[1]].LeftType == JoinCurl {
+		theta[idx[0]], phi[idx[1]] = 0, 0
+		return nil
+	}
+
+	chords := make([]Point, m)
+	d := make([]float64, m)
+	for j := 0; j < m; j++ {
+		chords[j] = vSub(knots[idx[j+1]].Point, knots[idx[j]].Point)
+		d[j] = chords[j].Length()
+	}
+	// psi[j] is the turning angle at the j-th knot of the piece; psi[m] is
+	// artificially defined to be zero, as in CreateHobbySpline
+	psi := make([]float64, m+1)
+	for j := 1; j < m; j++ {
+		psi[j] = vAngleBetween(chords[j-1], chords[j])
+	}
+	// alpha[j] is the reciprocal tension leaving knot j and beta[j] the one
+	// arriving at knot j
+	alpha := make([]float64, m+1)
+	beta := make([]float64, m+1)
+	for j := 0; j <= m; j++ {
+		alpha[j] = reciprocalTension(knots[idx[j]].RightTension)
+		beta[j] = reciprocalTension(knots[idx[j]].LeftTension)
+	}
+
+	// The system of equations is tridiagonal, like in CreateHobbySpline, but
+	// with tensions and more kinds of end conditions (Knuth, METAFONT: The
+	// Program, §274ff).
+	A := make([]float64, m+1)
+	B := make([]float64, m+1)
+	C := make([]float64, m+1)
+	D := make([]float64, m+1)
+
+	first := knots[idx[0]]
+	if first.RightType == JoinGiven {
+		B[0] = 1
+		D[0] = normalizeAngle(first.RightAngle - chords[0].Angle())
+	} else {
+		chi := first.RightCurl * alpha[0] * alpha[0] / (beta[1] * beta[1])
+		B[0] = chi*alpha[0] + 3 - beta[1]
+		C[0] = chi*(3-alpha[0]) + beta[1]
+		D[0] = -1 * C[0] * psi[1]
+	}
+
+	for j := 1; j < m; j++ {
+		A[j], B[j], C[j], D[j] = curvatureEquation(d[j-1], d[j], psi[j], psi[j+1], alpha[j-1], beta[j], alpha[j], beta[j+1])
+	}
+
+	last := knots[idx[m]]
+	if last.LeftType == JoinGiven {
+		// With psi[m] = 0, theta[m] is simply -phi[m]
+		B[m] = 1
+		D[m] = -1 * normalizeAngle(chords[m-1].Angle()-last.LeftAngle)
+	} else {
+		chi := last.LeftCurl * beta[m] * beta[m] / (alpha[m-1] * alpha[m-1])
+		A[m] = alpha[m-1] + chi*(3-beta[m])
+		B[m] = 3 - alpha[m-1] + chi*beta[m]
+	}
+
+	x := thomas(A, B, C, D)
+	if math.IsNaN(x[0]) {
+		return errors.New("path has no solution")
+	}
+	for j := 0; j < m; j++ {
+		theta[idx[j]] = x[j]
+		phi[idx[j+1]] = -1*psi[j+1] - x[j+1]
+	}
+	return nil
+}
+
+// solveCycle solves for the angles of a cyclic path without constraints,
+// where the system of equations wraps around from the last knot to the first.
+func solveCycle(knots []Knot, theta, phi []float64) {
+	n := len(knots)
+
+	chords := make([]Point, n)
+	d := make([]float64, n)
+	for j := 0; j < n; j++ {
+		chords[j] = vSub(knots[(j+1)%n].Point, knots[j].Point)
+		d[j] = chords[j].Length()
+	}
+	psi := make([]float64, n)
+	for j := 0; j < n; j++ {
+		psi[j] = vAngleBetween(chords[(j+n-1)%n], chords[j])
+	}
+
+	alpha := make([]float64, n)
+	beta := make([]float64, n)
+	for j := 0; j < n; j++ {
+		alpha[j] = reciprocalTension(knots[j].RightTension)
+		beta[j] = reciprocalTension(knots[j].LeftTension)
+	}
+
+	x := cyclicAngles(d, psi, alpha, beta)
+	for j := 0; j < n; j++ {
+		theta[j] = x[j]
+		phi[j] = -1*psi[j] - x[j]
+	}
+}
+
+// controlsFor returns the control points of the segment from k to q, given
+// the angle it leaves k at relative to the chord, and the angle it arrives
+// at q at.
+func controlsFor(k, q Knot, theta, phi float64) (Point, Point) {
+	chord := vSub(q.Point, k.Point)
+	st, ct := math.Sin(theta), math.Cos(theta)
+	sf, cf := math.Sin(phi), math.Cos(phi)
+
+	rr := velocity(st, ct, sf, cf, math.Abs(orDefault(k.RightTension, 1)))
+	ss := velocity(sf, cf, st, ct, math.Abs(orDefault(q.LeftTension, 1)))
+
+	// "tension atleast" shortens the handles if they would otherwise cross
+	// the lines the path leaves and arrives along
+	if (k.RightTension < 0 || q.LeftTension < 0) && ((st >= 0 && sf >= 0) || (st <= 0 && sf <= 0)) {
+		sine := math.Abs(st)*cf + math.Abs(sf)*ct
+		if sine > 0 {
+			sine *= 1 + 1.0/65536
+			if k.RightTension < 0 && math.Abs(sf) < rr*sine {
+				rr = math.Abs(sf) / sine
+			}
+			if q.LeftTension < 0 && math.Abs(st) < ss*sine {
+				ss = math.Abs(st) / sine
+			}
+		}
+	}
+
+	c0 := vAdd(k.Point, Scale(Rotate(chord, theta), rr))
+	c1 := vSub(q.Point, Scale(Rotate(chord, -1*phi), ss))
+	return c0, c1
+}
+
+// velocity is Hobby's velocity function, as used by MetaPost: the length of
+// a handle as a fraction of the chord, given the sines and cosines of the
+// angles at either end and the tension. Like rho, it aims to approximate
+// circular arcs well.
+func velocity(st, ct, sf, cf, tension float64) float64 {
+	num := 2 + math.Sqrt2*(st-sf/16)*(sf-st/16)*(ct-cf)
+	denom := 3 * (1 + 0.5*(math.Sqrt(5)-1)*ct + 0.5*(3-math.Sqrt(5))*cf)
+	if num >= 4*tension*denom {
+		return 4
+	}
+	return num / (tension * denom)
+}
+
+// normalizeAngle maps an angle into (-π, π].
+func normalizeAngle(a float64) float64 {
+	a = math.Mod(a, 2*math.Pi)
+	if a > math.Pi {
+		a -= 2 * math.Pi
+	} else if a <= -math.Pi {
+		a += 2 * math.Pi
+	}
+	return a
+}
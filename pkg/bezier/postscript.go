@@ -0,0 +1,161 @@
+package bezier
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image/color"
+	"io"
+)
+
+// PrintOptions controls the output of WriteEPS and WritePDF. Spline
+// coordinates are used as-is, in PostScript points (1/72 inch), with the
+// origin at the top left and Y pointing down like on screen.
+type PrintOptions struct {
+	Width  float64
+	Height float64
+	// Background fills the whole page. Leave nil for none.
+	Background  color.Color
+	StrokeColor color.Color
+	StrokeWidth float64
+	// Others are further splines, drawn underneath the main one with
+	// StrokeWidth.
+	Others []Stroke
+}
+
+// WriteEPS writes the spline as an Encapsulated PostScript file.
+func WriteEPS(w io.Writer, spline []Point, opts PrintOptions) error {
+	if _, err := Segments(spline); err != nil {
+		return err
+	}
+	if err := checkStrokes(opts.Others); err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("%!PS-Adobe-3.0 EPSF-3.0\n")
+	fmt.Fprintf(bw, "%%%%BoundingBox: 0 0 %d %d\n", int(opts.Width+0.999), int(opts.Height+0.999))
+	fmt.Fprintf(bw, "%%%%HiResBoundingBox: 0 0 %s %s\n", psNumber(opts.Width), psNumber(opts.Height))
+	bw.WriteString("%%Creator: hobby-spline\n")
+	bw.WriteString("%%EndComments\n")
+	bw.WriteString("gsave\n")
+
+	if opts.Background != nil {
+		fmt.Fprintf(bw, "%s setrgbcolor\n", psColor(opts.Background))
+		fmt.Fprintf(bw, "0 0 %s %s rectfill\n", psNumber(opts.Width), psNumber(opts.Height))
+	}
+
+	// Flip the Y axis so the spline can be written in screen coordinates
+	fmt.Fprintf(bw, "0 %s translate 1 -1 scale\n", psNumber(opts.Height))
+	fmt.Fprintf(bw, "%s setlinewidth 1 setlinecap 1 setlinejoin\n", psNumber(orDefault(opts.StrokeWidth, 1)))
+	for _, s := range opts.Others {
+		fmt.Fprintf(bw, "%s setrgbcolor\n", psColor(s.Color))
+		bw.WriteString("newpath\n")
+		writePathOperators(bw, s.Spline, "moveto", "curveto")
+		bw.WriteString("stroke\n")
+	}
+	fmt.Fprintf(bw, "%s setrgbcolor\n", psColor(opts.StrokeColor))
+	bw.WriteString("newpath\n")
+	writePathOperators(bw, spline, "moveto", "curveto")
+	bw.WriteString("stroke\n")
+
+	bw.WriteString("grestore\n")
+	bw.WriteString("showpage\n")
+	bw.WriteString("%%EOF\n")
+	return bw.Flush()
+}
+
+// PDFContent returns a PDF content stream that draws the spline, for
+// embedding in a page of a larger document.
+func PDFContent(spline []Point, opts PrintOptions) ([]byte, error) {
+	if _, err := Segments(spline); err != nil {
+		return nil, err
+	}
+	if err := checkStrokes(opts.Others); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	bw.WriteString("q\n")
+	if opts.Background != nil {
+		fmt.Fprintf(bw, "%s rg\n", psColor(opts.Background))
+		fmt.Fprintf(bw, "0 0 %s %s re f\n", psNumber(opts.Width), psNumber(opts.Height))
+	}
+	fmt.Fprintf(bw, "1 0 0 -1 0 %s cm\n", psNumber(opts.Height))
+	fmt.Fprintf(bw, "%s w 1 J 1 j\n", psNumber(orDefault(opts.StrokeWidth, 1)))
+	for _, s := range opts.Others {
+		fmt.Fprintf(bw, "%s RG\n", psColor(s.Color))
+		writePathOperators(bw, s.Spline, "m", "c")
+		bw.WriteString("S\n")
+	}
+	fmt.Fprintf(bw, "%s RG\n", psColor(opts.StrokeColor))
+	writePathOperators(bw, spline, "m", "c")
+	bw.WriteString("S\n")
+	bw.WriteString("Q\n")
+	if err := bw.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WritePDF writes the spline as a single-page PDF document.
+func WritePDF(w io.Writer, spline []Point, opts PrintOptions) error {
+	content, err := PDFContent(spline, opts)
+	if err != nil {
+		return err
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Contents 4 0 R >>",
+			psNumber(opts.Width), psNumber(opts.Height)),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content),
+	}
+
+	// Track the byte offset of every object for the cross-reference table
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n", len(objects)+1)
+	buf.WriteString("0000000000 65535 f \n")
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// writePathOperators writes the spline as PostScript-style path operators,
+// which PDF shares under shorter names.
+func writePathOperators(w io.Writer, spline []Point, moveTo string, curveTo string) {
+	fmt.Fprintf(w, "%s %s %s\n", psNumber(spline[0].X), psNumber(spline[0].Y), moveTo)
+	for i := 1; i+2 < len(spline); i += 3 {
+		fmt.Fprintf(w, "%s %s %s %s %s %s %s\n",
+			psNumber(spline[i].X), psNumber(spline[i].Y),
+			psNumber(spline[i+1].X), psNumber(spline[i+1].Y),
+			psNumber(spline[i+2].X), psNumber(spline[i+2].Y),
+			curveTo)
+	}
+}
+
+func psNumber(f float64) string {
+	return svgNumber(f)
+}
+
+func psColor(c color.Color) string {
+	if c == nil {
+		return "0 0 0"
+	}
+	r, g, b, _ := c.RGBA()
+	return psNumber(float64(r)/0xffff) + " " + psNumber(float64(g)/0xffff) + " " + psNumber(float64(b)/0xffff)
+}
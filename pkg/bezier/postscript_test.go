@@ -0,0 +1,135 @@
+package bezier
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var printSpline = []Point{
+	{X: 10, Y: 10}, {X: 20, Y: 0}, {X: 40, Y: 0}, {X: 50, Y: 10},
+	{X: 60, Y: 20}, {X: 80, Y: 20}, {X: 90, Y: 10},
+}
+
+func TestWriteEPS(t *testing.T) {
+	var buf bytes.Buffer
+	opts := PrintOptions{
+		Width: 100.5, Height: 40, StrokeWidth: 2,
+		Background:  color.White,
+		StrokeColor: color.RGBA{R: 255, A: 255},
+		Others:      []Stroke{{Spline: printSpline[:4], Color: color.Black}},
+	}
+	if err := WriteEPS(&buf, printSpline, opts); err != nil {
+		t.Fatal(err)
+	}
+	eps := buf.String()
+	lines := strings.Split(strings.TrimSuffix(eps, "\n"), "\n")
+
+	if lines[0] != "%!PS-Adobe-3.0 EPSF-3.0" {
+		t.Errorf("first line is %q", lines[0])
+	}
+	if !strings.Contains(eps, "\n%%BoundingBox: 0 0 101 40\n") || !strings.Contains(eps, "\n%%HiResBoundingBox: 0 0 100.5 40\n") {
+		t.Errorf("no bounding box of the page in:\n%s", eps)
+	}
+	if lines[len(lines)-1] != "%%EOF" || lines[len(lines)-2] != "showpage" {
+		t.Errorf("doesn't end by showing the page: %q", lines[len(lines)-2:])
+	}
+	if got := strings.Count(eps, " curveto\n"); got != 3 {
+		t.Errorf("%d curves, want 3", got)
+	}
+	if got := strings.Count(eps, " moveto\n"); got != 2 {
+		t.Errorf("%d paths, want 2", got)
+	}
+	if !strings.Contains(eps, "\n1 0 0 setrgbcolor\nnewpath\n10 10 moveto\n20 0 40 0 50 10 curveto\n60 20 80 20 90 10 curveto\nstroke\n") {
+		t.Errorf("spline isn't stroked in red:\n%s", eps)
+	}
+	if strings.Count(eps, "gsave") != strings.Count(eps, "grestore") {
+		t.Error("unbalanced gsave and grestore")
+	}
+}
+
+var pdfObject = regexp.MustCompile(`^(\d+) 0 obj\n`)
+
+func TestWritePDF(t *testing.T) {
+	var buf bytes.Buffer
+	opts := PrintOptions{Width: 100, Height: 40, StrokeColor: color.Black}
+	if err := WritePDF(&buf, printSpline, opts); err != nil {
+		t.Fatal(err)
+	}
+	pdf := buf.Bytes()
+
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) {
+		t.Fatalf("header is %q", pdf[:min(len(pdf), 9)])
+	}
+	if !bytes.HasSuffix(pdf, []byte("\n%%EOF\n")) {
+		t.Fatalf("ends with %q", pdf[max(0, len(pdf)-8):])
+	}
+
+	// startxref gives the offset of the cross-reference table
+	tail := pdf[bytes.LastIndex(pdf, []byte("startxref\n")):]
+	var xref int
+	if _, err := fmt.Sscanf(string(tail), "startxref\n%d\n", &xref); err != nil {
+		t.Fatal(err)
+	}
+	table := string(pdf[xref:])
+	if !strings.HasPrefix(table, "xref\n") {
+		t.Fatalf("startxref %d doesn't point at the cross-reference table", xref)
+	}
+	lines := strings.Split(table, "\n")
+	var first, count int
+	if _, err := fmt.Sscanf(lines[1], "%d %d", &first, &count); err != nil || first != 0 {
+		t.Fatalf("bad subsection header %q", lines[1])
+	}
+	if lines[2] != "0000000000 65535 f " {
+		t.Errorf("object 0 entry is %q", lines[2])
+	}
+	// Every entry is 20 bytes and points at its object
+	for i := 1; i < count; i++ {
+		entry := lines[2+i]
+		if len(entry)+1 != 20 || !strings.HasSuffix(entry, " 00000 n ") {
+			t.Fatalf("bad entry %q", entry)
+		}
+		offset, err := strconv.Atoi(entry[:10])
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := pdfObject.FindSubmatch(pdf[offset:])
+		if m == nil || string(m[1]) != strconv.Itoa(i) {
+			t.Fatalf("entry %d points at %q", i, pdf[offset:min(len(pdf), offset+12)])
+		}
+	}
+	if !strings.Contains(table, fmt.Sprintf("trailer\n<< /Size %d /Root 1 0 R >>", count)) {
+		t.Errorf("trailer doesn't match the table:\n%s", table)
+	}
+
+	// The content stream is as long as it says
+	content, err := PDFContent(printSpline, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream := fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content)
+	if !bytes.Contains(pdf, []byte(stream)) {
+		t.Errorf("no stream of the content, %d bytes long", len(content))
+	}
+	if !bytes.Contains(pdf, []byte("/MediaBox [0 0 100 40]")) {
+		t.Error("page isn't the size asked for")
+	}
+}
+
+func TestPrintErrors(t *testing.T) {
+	var buf bytes.Buffer
+	bad := printSpline[:3]
+	if err := WriteEPS(&buf, bad, PrintOptions{}); err == nil {
+		t.Error("wrote an EPS of a spline that isn't in the 3n - 2 layout")
+	}
+	if err := WritePDF(&buf, bad, PrintOptions{}); err == nil {
+		t.Error("wrote a PDF of a spline that isn't in the 3n - 2 layout")
+	}
+	if err := WritePDF(&buf, printSpline, PrintOptions{Others: []Stroke{{Spline: bad}}}); err == nil {
+		t.Error("wrote a PDF with another spline that isn't in the 3n - 2 layout")
+	}
+}
@@ -0,0 +1,192 @@
+package bezier
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+
+	"golang.org/x/image/vector"
+)
+
+// rasterTolerance is how far, in pixels, flattened curves may stray from the
+// true curve when rasterizing.
+const rasterTolerance = 0.1
+
+// circleSides is the number of sides of the polygons used to draw knots and
+// round line caps.
+const circleSides = 32
+
+// Rasterize draws the spline and the optional extras in opts into a new
+// image of opts.Width by opts.Height pixels, with anti-aliasing. It draws the
+// same things as WriteSVG, but without needing a browser or GPU.
+func Rasterize(spline []Point, opts RenderOptions) (*image.RGBA, error) {
+	curves, err := Segments(spline)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStrokes(opts.Others); err != nil {
+		return nil, err
+	}
+
+	w, h := int(math.Ceil(opts.Width)), int(math.Ceil(opts.Height))
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	if opts.Background != nil {
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(opts.Background), image.Point{}, draw.Src)
+	}
+
+	for _, s := range opts.Others {
+		others, _ := Segments(s.Spline)
+		strokePolyline(dst, flattenCurves(others), orDefault(opts.CurveWidth, 1), s.Color)
+	}
+
+	if len(opts.Natural) != 0 {
+		if natural, err := Segments(opts.Natural); err == nil {
+			for _, curve := range natural {
+				strokePolyline(dst, curve.Flatten(rasterTolerance), orDefault(opts.NaturalWidth, 1), opts.NaturalColor)
+			}
+		}
+	}
+
+	if opts.Comb {
+		for _, curve := range curves {
+			comb := curve.Comb(orDefault(opts.CombSpacing, 5), opts.CombScale)
+			for i, tooth := range comb {
+				c := opts.CurveColor
+				if opts.CombColor != nil {
+					c = opts.CombColor(i, len(comb))
+				}
+				strokePolyline(dst, tooth[:], 1, c)
+			}
+		}
+	}
+
+	strokePolyline(dst, flattenCurves(curves), orDefault(opts.CurveWidth, 1), opts.CurveColor)
+
+	for _, k := range opts.Knots {
+		var shape polygons
+		shape.addCircle(k, orDefault(opts.KnotRadius, 1))
+		shape.fill(dst, opts.KnotColor)
+	}
+
+	return dst, nil
+}
+
+// WritePNG rasterizes the spline as Rasterize does and encodes it as PNG.
+func WritePNG(w io.Writer, spline []Point, opts RenderOptions) error {
+	img, err := Rasterize(spline, opts)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// flattenCurves flattens consecutive curves into a single polyline.
+func flattenCurves(curves []*Bezier) []Point {
+	var polyline []Point
+	for i, curve := range curves {
+		pts := curve.Flatten(rasterTolerance)
+		if i > 0 {
+			pts = pts[1:]
+		}
+		polyline = append(polyline, pts...)
+	}
+	return polyline
+}
+
+// strokePolyline draws a polyline of the given width with round joins and
+// caps, as a union of one rectangle per line segment and one circle per
+// point.
+func strokePolyline(dst *image.RGBA, points []Point, width float64, c color.Color) {
+	var shape polygons
+	half := width / 2
+	for i := 1; i < len(points); i++ {
+		p0, p1 := points[i-1], points[i]
+		d := vSub(p1, p0)
+		if d.Length() == 0 {
+			continue
+		}
+		n := Scale(Normalize(Point{X: -d.Y, Y: d.X}), half)
+		shape = append(shape, []Point{vAdd(p0, n), vAdd(p1, n), vSub(p1, n), vSub(p0, n)})
+	}
+	if width > 1 {
+		for _, p := range points {
+			shape.addCircle(p, half)
+		}
+	}
+	shape.fill(dst, c)
+}
+
+// polygons is a set of closed polygons filled together as one shape. Each is
+// rasterized on its own, and the shape takes the greatest coverage of any of
+// them at each pixel, so that the anti-aliased edges of overlapping polygons
+// don't add up to a stroke that looks heavier than it is.
+type polygons [][]Point
+
+// addCircle adds a circle, approximated by a polygon of circleSides sides.
+func (s *polygons) addCircle(center Point, radius float64) {
+	circle := make([]Point, circleSides)
+	for i := range circle {
+		angle := -2 * math.Pi * float64(i) / circleSides
+		circle[i] = Point{X: center.X + radius*math.Cos(angle), Y: center.Y + radius*math.Sin(angle)}
+	}
+	*s = append(*s, circle)
+}
+
+// fill draws the shape onto dst. Rasterizing only the bounding box of each
+// polygon keeps small shapes, like comb teeth, cheap to draw.
+func (s polygons) fill(dst *image.RGBA, c color.Color) {
+	if c == nil {
+		c = color.Black
+	}
+
+	bounds := polygonBounds(s...).Intersect(dst.Bounds())
+	if bounds.Empty() {
+		return
+	}
+
+	mask := image.NewAlpha(bounds)
+	for _, poly := range s {
+		r := polygonBounds(poly).Intersect(bounds)
+		if r.Empty() {
+			continue
+		}
+		z := vector.NewRasterizer(r.Dx(), r.Dy())
+		ox, oy := float64(r.Min.X), float64(r.Min.Y)
+		z.MoveTo(float32(poly[0].X-ox), float32(poly[0].Y-oy))
+		for _, p := range poly[1:] {
+			z.LineTo(float32(p.X-ox), float32(p.Y-oy))
+		}
+		z.ClosePath()
+
+		coverage := image.NewAlpha(r)
+		z.Draw(coverage, r, image.Opaque, r.Min)
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				if a := coverage.AlphaAt(x, y); a.A > mask.AlphaAt(x, y).A {
+					mask.SetAlpha(x, y, a)
+				}
+			}
+		}
+	}
+	draw.DrawMask(dst, bounds, image.NewUniform(c), image.Point{}, mask, bounds.Min, draw.Over)
+}
+
+// polygonBounds returns the smallest rectangle of whole pixels containing
+// every polygon.
+func polygonBounds(polys ...[]Point) image.Rectangle {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, poly := range polys {
+		for _, p := range poly {
+			minX, maxX = math.Min(minX, p.X), math.Max(maxX, p.X)
+			minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+		}
+	}
+	if minX > maxX {
+		return image.Rectangle{}
+	}
+	return image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY)))
+}
@@ -0,0 +1,103 @@
+package bezier
+
+import (
+	"bytes"
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden images in testdata")
+
+// rasterCircle rasterizes a circle of radius 20 in the middle of a 64 by 64
+// image, two pixels wide, in black on white.
+func rasterCircle(t *testing.T) *image.RGBA {
+	t.Helper()
+	path, err := ParseMetaPost("(52,32)..(32,52)..(12,32)..(32,12)..cycle", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spline, err := path.Solve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := Rasterize(spline, RenderOptions{
+		Width:      64,
+		Height:     64,
+		Background: color.White,
+		CurveColor: color.Black,
+		CurveWidth: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestRasterizeCoverage(t *testing.T) {
+	img := rasterCircle(t)
+
+	// The ink adds up to the area of the ring the stroke covers, give or
+	// take the pixels split between pieces of the stroke
+	var ink float64
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			ink += 1 - float64(img.RGBAAt(x, y).R)/255
+		}
+	}
+	want := math.Pi * (21*21 - 19*19)
+	if math.Abs(ink-want) > 0.05*want {
+		t.Errorf("stroke covers %.1f pixels, want %.1f", ink, want)
+	}
+
+	for _, p := range []image.Point{{32, 32}, {0, 0}, {63, 63}, {32, 40}} {
+		if c := img.RGBAAt(p.X, p.Y); c != (color.RGBA{255, 255, 255, 255}) {
+			t.Errorf("pixel %v is %v, want the background", p, c)
+		}
+	}
+	for _, p := range []image.Point{{51, 32}, {32, 51}, {12, 31}, {31, 12}} {
+		if c := img.RGBAAt(p.X, p.Y); c.R > 64 {
+			t.Errorf("pixel %v is %v, want it on the stroke", p, c)
+		}
+	}
+}
+
+func TestRasterizeGolden(t *testing.T) {
+	img := rasterCircle(t)
+	golden := filepath.Join("testdata", "circle.png")
+
+	if *update {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := os.Open(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	want, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want.Bounds() != img.Bounds() {
+		t.Fatalf("image is %v, want %v", img.Bounds(), want.Bounds())
+	}
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			if got := img.At(x, y); color.RGBAModel.Convert(want.At(x, y)) != got {
+				t.Fatalf("pixel (%d,%d) is %v, want %v; rerun with -update if the change is intended", x, y, got, want.At(x, y))
+			}
+		}
+	}
+}
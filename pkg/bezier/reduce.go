@@ -0,0 +1,100 @@
+package bezier
+
+import (
+	"errors"
+	"slices"
+)
+
+// ReduceKnots: given many sampled points along a path, choose a small subset
+// of them as knots such that the Hobby spline through those knots (as built by
+// CreateHobbySpline with the given omega) passes within tolerance of every
+// sample. The returned knots are in their original order and always include
+// the first and last sample.
+//
+// Knots are chosen greedily: starting from the two end points, the sample
+// furthest from the current spline is promoted to a knot until every sample
+// is within tolerance. Since Hobby splines are global, a final pass then drops
+// any knot the spline turns out not to need. This does not guarantee the
+// smallest possible subset, but in practice comes close.
+func ReduceKnots(samples []Point, omega float64, tolerance float64) ([]Point, error) {
+	if tolerance <= 0 {
+		return nil, errors.New("tolerance must be positive")
+	}
+
+	// Successive duplicates would produce zero-length chords
+	var points []Point
+	for _, p := range samples {
+		if len(points) == 0 || vDistance(points[len(points)-1], p) > 0 {
+			points = append(points, Point{X: p.X, Y: p.Y})
+		}
+	}
+	if len(points) < 2 {
+		return nil, errors.New("not enough points")
+	}
+
+	// knots holds indices into points
+	knots := []int{0, len(points) - 1}
+	for {
+		worst, err := worstSample(points, knots, omega, tolerance)
+		if err != nil {
+			return nil, err
+		}
+		if worst < 0 {
+			break
+		}
+		i, _ := slices.BinarySearch(knots, worst)
+		knots = slices.Insert(knots, i, worst)
+	}
+
+	// Try removing each interior knot, keeping the removal if the spline
+	// still fits
+	for i := 1; i < len(knots)-1; {
+		candidate := slices.Delete(slices.Clone(knots), i, i+1)
+		worst, err := worstSample(points, candidate, omega, tolerance)
+		if err != nil {
+			return nil, err
+		}
+		if worst < 0 {
+			knots = candidate
+		} else {
+			i++
+		}
+	}
+
+	result := make([]Point, len(knots))
+	for i, k := range knots {
+		result[i] = points[k]
+	}
+	return result, nil
+}
+
+// worstSample fits a Hobby spline through the given knots and returns the
+// index of the sample furthest from it, or -1 if every sample is within
+// tolerance.
+func worstSample(points []Point, knots []int, omega float64, tolerance float64) (int, error) {
+	selected := make([]Point, len(knots))
+	for i, k := range knots {
+		selected[i] = points[k]
+	}
+	spline, err := CreateHobbySpline(selected, omega)
+	if err != nil {
+		return -1, err
+	}
+	curves, err := Segments(spline)
+	if err != nil {
+		return -1, err
+	}
+
+	// Each sample is measured against the segment between the knots that
+	// bracket it
+	worst, worstDist := -1, tolerance
+	for s, curve := range curves {
+		for j := knots[s] + 1; j < knots[s+1]; j++ {
+			closest, _ := curve.Project(points[j])
+			if d := vDistance(closest, points[j]); d > worstDist {
+				worst, worstDist = j, d
+			}
+		}
+	}
+	return worst, nil
+}
@@ -0,0 +1,90 @@
+package bezier
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestReduceKnotsTolerance(t *testing.T) {
+	hobby, err := CreateHobbySpline([]Point{{X: 0, Y: 0}, {X: 100, Y: 80}, {X: 200, Y: -40}, {X: 260, Y: 90}, {X: 380, Y: 0}}, 0.75)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spiral []Point
+	for i := 0; i <= 300; i++ {
+		a := float64(i) / 300 * 3 * math.Pi
+		spiral = append(spiral, Point{X: (20 + 10*a) * math.Cos(a), Y: (20 + 10*a) * math.Sin(a)})
+	}
+
+	tests := []struct {
+		name    string
+		samples []Point
+	}{
+		{name: "hobby", samples: sampleSpline(t, hobby, 60)},
+		{name: "spiral", samples: spiral},
+	}
+	for _, tc := range tests {
+		for _, tolerance := range []float64{2, 0.5, 0.1} {
+			knots, err := ReduceKnots(tc.samples, 0.75, tolerance)
+			if err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+			if len(knots) >= len(tc.samples)/4 {
+				t.Errorf("%s at %g: kept %d of %d samples", tc.name, tolerance, len(knots), len(tc.samples))
+			}
+
+			// The knots are samples, in order, starting and ending with
+			// the first and last
+			if knots[0] != tc.samples[0] || knots[len(knots)-1] != tc.samples[len(tc.samples)-1] {
+				t.Fatalf("%s: knots don't start and end with the samples", tc.name)
+			}
+			at := 0
+			for _, k := range knots {
+				i := slices.Index(tc.samples[at:], k)
+				if i < 0 {
+					t.Fatalf("%s: knot %v isn't a later sample", tc.name, k)
+				}
+				at += i + 1
+			}
+
+			spline, err := CreateHobbySpline(knots, 0.75)
+			if err != nil {
+				t.Fatal(err)
+			}
+			const flatness = 1e-3
+			polyline := flattenSpline(t, spline, flatness)
+			worst := 0.0
+			for _, p := range tc.samples {
+				worst = math.Max(worst, polylineDistance(p, polyline))
+			}
+			if worst > tolerance+flatness {
+				t.Errorf("%s: a sample is %g from the reduced spline, want at most %g", tc.name, worst, tolerance)
+			}
+		}
+	}
+}
+
+func TestReduceKnotsLine(t *testing.T) {
+	var samples []Point
+	for i := 0; i <= 100; i++ {
+		samples = append(samples, Point{X: float64(i), Y: float64(i) / 2})
+		// Repeated samples are skipped
+		samples = append(samples, Point{X: float64(i), Y: float64(i) / 2})
+	}
+	knots, err := ReduceKnots(samples, 0.75, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Point{{X: 0, Y: 0}, {X: 100, Y: 50}}
+	if !slices.Equal(knots, want) {
+		t.Fatalf("reduced a line to %v, want %v", knots, want)
+	}
+
+	if _, err := ReduceKnots(samples[:2], 0.75, 1); err == nil {
+		t.Error("reduced a single repeated sample")
+	}
+	if _, err := ReduceKnots(samples, 0.75, 0); err == nil {
+		t.Error("reduced with a tolerance of 0")
+	}
+}
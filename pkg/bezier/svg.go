@@ -0,0 +1,169 @@
+package bezier
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// SVGPath returns the SVG path data for a spline in the 3n - 2 layout of
+// CreateHobbySpline, i.e. a move to the first knot followed by one cubic
+// Bézier command per segment.
+func SVGPath(spline []Point) string {
+	if len(spline) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("M " + svgCoords(spline[0]))
+	for i := 1; i+2 < len(spline); i += 3 {
+		sb.WriteString(" C " + svgCoords(spline[i]) + " " + svgCoords(spline[i+1]) + " " + svgCoords(spline[i+2]))
+	}
+	return sb.String()
+}
+
+// RenderOptions controls what WriteSVG and Rasterize draw and how it looks.
+// Zero-valued colors and widths fall back to black and 1 respectively.
+type RenderOptions struct {
+	Width  float64
+	Height float64
+	// Background fills the whole image. Leave nil for transparency.
+	Background color.Color
+
+	CurveColor color.Color
+	CurveWidth float64
+
+	// Others are further splines, drawn underneath the main one and its
+	// extras with CurveWidth.
+	Others []Stroke
+
+	// Knots are drawn as filled circles when non-empty.
+	Knots      []Point
+	KnotColor  color.Color
+	KnotRadius float64
+
+	// Natural is an optional second spline, e.g. from NaturalCubicSpline,
+	// drawn underneath the main one.
+	Natural      []Point
+	NaturalColor color.Color
+	NaturalWidth float64
+
+	// Comb adds the curvature comb of the spline as line elements, with
+	// teeth every CombSpacing units and CombScale as in Bezier.Comb.
+	Comb        bool
+	CombSpacing float64
+	CombScale   float64
+	// CombColor picks the color of tooth i of a segment's teeth. Leave nil
+	// to draw every tooth in CurveColor.
+	CombColor func(i int, teeth int) color.Color
+}
+
+// Stroke is a spline drawn in a color of its own.
+type Stroke struct {
+	Spline []Point
+	Color  color.Color
+}
+
+// checkStrokes returns an error if any of strokes isn't a valid spline.
+func checkStrokes(strokes []Stroke) error {
+	for _, s := range strokes {
+		if _, err := Segments(s.Spline); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteSVG writes a complete SVG document containing the spline and the
+// optional extras in opts.
+func WriteSVG(w io.Writer, spline []Point, opts RenderOptions) error {
+	curves, err := Segments(spline)
+	if err != nil {
+		return err
+	}
+	if err := checkStrokes(opts.Others); err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s">`+"\n",
+		svgNumber(opts.Width), svgNumber(opts.Height), svgNumber(opts.Width), svgNumber(opts.Height))
+
+	if opts.Background != nil {
+		fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", svgColor(opts.Background))
+	}
+
+	for _, s := range opts.Others {
+		fmt.Fprintf(bw, `<path d="%s" fill="none" stroke="%s" stroke-width="%s" stroke-linecap="round"/>`+"\n",
+			SVGPath(s.Spline), svgColor(s.Color), svgNumber(orDefault(opts.CurveWidth, 1)))
+	}
+
+	if len(opts.Natural) != 0 {
+		fmt.Fprintf(bw, `<path d="%s" fill="none" stroke="%s" stroke-width="%s"/>`+"\n",
+			SVGPath(opts.Natural), svgColor(opts.NaturalColor), svgNumber(orDefault(opts.NaturalWidth, 1)))
+	}
+
+	if opts.Comb {
+		bw.WriteString(`<g stroke-width="1">` + "\n")
+		for _, curve := range curves {
+			comb := curve.Comb(orDefault(opts.CombSpacing, 5), opts.CombScale)
+			for i, tooth := range comb {
+				c := opts.CurveColor
+				if opts.CombColor != nil {
+					c = opts.CombColor(i, len(comb))
+				}
+				fmt.Fprintf(bw, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="%s"/>`+"\n",
+					svgNumber(tooth[0].X), svgNumber(tooth[0].Y), svgNumber(tooth[1].X), svgNumber(tooth[1].Y), svgColor(c))
+			}
+		}
+		bw.WriteString("</g>\n")
+	}
+
+	fmt.Fprintf(bw, `<path d="%s" fill="none" stroke="%s" stroke-width="%s" stroke-linecap="round"/>`+"\n",
+		SVGPath(spline), svgColor(opts.CurveColor), svgNumber(orDefault(opts.CurveWidth, 1)))
+
+	if len(opts.Knots) != 0 {
+		fmt.Fprintf(bw, `<g fill="%s">`+"\n", svgColor(opts.KnotColor))
+		for _, k := range opts.Knots {
+			fmt.Fprintf(bw, `<circle cx="%s" cy="%s" r="%s"/>`+"\n",
+				svgNumber(k.X), svgNumber(k.Y), svgNumber(orDefault(opts.KnotRadius, 1)))
+		}
+		bw.WriteString("</g>\n")
+	}
+
+	bw.WriteString("</svg>\n")
+	return bw.Flush()
+}
+
+func svgCoords(p Point) string {
+	return svgNumber(p.X) + " " + svgNumber(p.Y)
+}
+
+// svgNumber formats f with up to three decimals, which is plenty for print,
+// dropping trailing zeros.
+func svgNumber(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 3, 64)
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+func svgColor(c color.Color) string {
+	if c == nil {
+		return "#000000"
+	}
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+func orDefault(f float64, def float64) float64 {
+	if f == 0 {
+		return def
+	}
+	return f
+}
@@ -0,0 +1,460 @@
+package bezier
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// SVGSubpath is one connected run of segments from SVG path data, as started
+// by a moveto command.
+type SVGSubpath struct {
+	// Segments are quadratic or cubic Bézier curves. Lines and arcs are
+	// converted to cubics.
+	Segments []*Bezier
+	// Closed is set if the subpath ended with a closepath command. The
+	// closing line, if any, is included in Segments.
+	Closed bool
+}
+
+// OnCurvePoints returns the end points of the subpath's segments, i.e. the
+// points the path passes through, without repeating the first point for
+// closed subpaths. These can be fed back into CreateHobbySpline to re-fit the
+// path.
+func (s SVGSubpath) OnCurvePoints() []Point {
+	return onCurvePoints(s.Segments, s.Closed)
+}
+
+// Spline returns the subpath as a single spline in the 3n - 2 layout of
+// CreateHobbySpline, raising quadratic segments to cubics.
+func (s SVGSubpath) Spline() []Point {
+	return joinSegments(s.Segments)
+}
+
+// onCurvePoints returns the distinct end points of a run of segments. If the
+// run is closed, its first point isn't repeated at the end.
+func onCurvePoints(segments []*Bezier, closed bool) []Point {
+	if len(segments) == 0 {
+		return nil
+	}
+	points := []Point{segments[0].Points[0]}
+	for _, seg := range segments {
+		end := seg.Points[len(seg.Points)-1]
+		if vDistance(points[len(points)-1], end) > 0 {
+			points = append(points, end)
+		}
+	}
+	if closed && len(points) > 1 && vDistance(points[0], points[len(points)-1]) == 0 {
+		points = points[:len(points)-1]
+	}
+	return points
+}
+
+// joinSegments returns a run of quadratic and cubic segments as a single
+// spline of cubics.
+func joinSegments(segments []*Bezier) []Point {
+	var spline []Point
+	for i, seg := range segments {
+		p := seg.Points
+		if len(p) == 3 {
+			// Degree elevation: the same curve as a cubic
+			p = []Point{
+				p[0],
+				vAdd(p[0], Scale(vSub(p[1], p[0]), 2.0/3)),
+				vAdd(p[2], Scale(vSub(p[1], p[2]), 2.0/3)),
+				p[2],
+			}
+		}
+		if i == 0 {
+			spline = append(spline, p[0])
+		}
+		spline = append(spline, p[1], p[2], p[3])
+	}
+	return spline
+}
+
+// lineSegment returns the straight line from start to end as a cubic.
+func lineSegment(start, end Point) *Bezier {
+	curve, _ := NewBezier(false, start, vAdd(start, Scale(vSub(end, start), 1.0/3)), vAdd(start, Scale(vSub(end, start), 2.0/3)), end)
+	return curve
+}
+
+// ParseSVGPath parses the data of an SVG path element (the d attribute),
+// supporting every command in both absolute and relative form:
+// M, L, H, V, C, S, Q, T, A and Z.
+func ParseSVGPath(d string) ([]SVGSubpath, error) {
+	p := &svgPathParser{data: d}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.subpaths, nil
+}
+
+type svgPathParser struct {
+	data string
+	pos  int
+
+	subpaths []SVGSubpath
+	current  *SVGSubpath
+	// cur is the current point and start the first point of the subpath
+	cur, start Point
+	// ctrl is the last control point, reflected by S and T commands
+	ctrl    Point
+	lastCmd byte
+}
+
+func (p *svgPathParser) parse() error {
+	p.skipSeparators()
+	if p.pos < len(p.data) && p.data[p.pos] != 'M' && p.data[p.pos] != 'm' {
+		return p.errorf("path data must start with a moveto command")
+	}
+
+	var cmd byte
+	for {
+		p.skipSeparators()
+		if p.pos >= len(p.data) {
+			return nil
+		}
+
+		c := p.data[p.pos]
+		if strings.IndexByte("MmLlHhVvCcSsQqTtAaZz", c) >= 0 {
+			cmd = c
+			p.pos++
+		} else if cmd == 0 || cmd == 'Z' || cmd == 'z' {
+			return p.errorf("expected a command, found %q", c)
+		}
+		// Otherwise this is an implicit repeat of the previous command
+
+		if err := p.command(cmd); err != nil {
+			return err
+		}
+
+		// Coordinates following a moveto are implicit linetos
+		if cmd == 'M' {
+			cmd = 'L'
+		} else if cmd == 'm' {
+			cmd = 'l'
+		}
+	}
+}
+
+func (p *svgPathParser) command(cmd byte) error {
+	relative := cmd >= 'a'
+	offset := func(pt Point) Point {
+		if relative {
+			return vAdd(pt, p.cur)
+		}
+		return pt
+	}
+
+	upper := cmd &^ 0x20
+	switch upper {
+	case 'M':
+		pt, err := p.point()
+		if err != nil {
+			return err
+		}
+		p.cur = offset(pt)
+		p.start = p.cur
+		p.subpaths = append(p.subpaths, SVGSubpath{})
+		p.current = &p.subpaths[len(p.subpaths)-1]
+
+	case 'L':
+		pt, err := p.point()
+		if err != nil {
+			return err
+		}
+		p.line(offset(pt))
+
+	case 'H', 'V':
+		n, err := p.number()
+		if err != nil {
+			return err
+		}
+		pt := p.cur
+		if upper == 'H' {
+			pt.X = n
+			if relative {
+				pt.X += p.cur.X
+			}
+		} else {
+			pt.Y = n
+			if relative {
+				pt.Y += p.cur.Y
+			}
+		}
+		p.line(pt)
+
+	case 'C', 'S':
+		var c1 Point
+		if upper == 'S' {
+			c1 = p.reflectedControl("CcSs")
+		} else {
+			pt, err := p.point()
+			if err != nil {
+				return err
+			}
+			c1 = offset(pt)
+		}
+		c2, err := p.point()
+		if err != nil {
+			return err
+		}
+		end, err := p.point()
+		if err != nil {
+			return err
+		}
+		c2, end = offset(c2), offset(end)
+		p.segment(p.cur, c1, c2, end)
+		p.ctrl = c2
+
+	case 'Q', 'T':
+		var c Point
+		if upper == 'T' {
+			c = p.reflectedControl("QqTt")
+		} else {
+			pt, err := p.point()
+			if err != nil {
+				return err
+			}
+			c = offset(pt)
+		}
+		end, err := p.point()
+		if err != nil {
+			return err
+		}
+		end = offset(end)
+		p.segment(p.cur, c, end)
+		p.ctrl = c
+
+	case 'A':
+		rx, err := p.number()
+		if err != nil {
+			return err
+		}
+		ry, err := p.number()
+		if err != nil {
+			return err
+		}
+		rotation, err := p.number()
+		if err != nil {
+			return err
+		}
+		largeArc, err := p.flag()
+		if err != nil {
+			return err
+		}
+		sweep, err := p.flag()
+		if err != nil {
+			return err
+		}
+		end, err := p.point()
+		if err != nil {
+			return err
+		}
+		p.arc(rx, ry, rotation, largeArc, sweep, offset(end))
+
+	case 'Z':
+		if vDistance(p.cur, p.start) > 0 {
+			p.line(p.start)
+		}
+		p.current.Closed = true
+		p.cur = p.start
+	}
+
+	p.lastCmd = cmd
+	return nil
+}
+
+// reflectedControl returns the first control point of a smooth curve command:
+// the reflection of the previous control point if the previous command was
+// one of prev, or the current point otherwise.
+func (p *svgPathParser) reflectedControl(prev string) Point {
+	if p.lastCmd != 0 && strings.IndexByte(prev, p.lastCmd) >= 0 {
+		return vSub(Scale(p.cur, 2), p.ctrl)
+	}
+	return p.cur
+}
+
+// segment appends a Bézier with the given points to the current subpath,
+// starting a new subpath at the current point after a closepath.
+func (p *svgPathParser) segment(points ...Point) {
+	if p.current.Closed {
+		p.subpaths = append(p.subpaths, SVGSubpath{})
+		p.current = &p.subpaths[len(p.subpaths)-1]
+		p.start = p.cur
+	}
+	curve, _ := NewBezier(false, points...)
+	p.current.Segments = append(p.current.Segments, curve)
+	p.cur = points[len(points)-1]
+}
+
+// line appends a straight line to end, as a cubic.
+func (p *svgPathParser) line(end Point) {
+	p.segment(lineSegment(p.cur, end).Points...)
+}
+
+// arc appends an elliptical arc to end, converted to cubics of at most a
+// quarter turn each. See the SVG implementation notes, "Conversion from
+// endpoint to center parameterization".
+func (p *svgPathParser) arc(rx, ry, rotation float64, largeArc, sweep bool, end Point) {
+	start := p.cur
+	if vDistance(start, end) == 0 {
+		return
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		p.line(end)
+		return
+	}
+
+	phi := rotation * math.Pi / 180
+	sinPhi, cosPhi := math.Sin(phi), math.Cos(phi)
+
+	// Step 1: compute (x1', y1'), the midpoint in the ellipse's frame
+	dx, dy := (start.X-end.X)/2, (start.Y-end.Y)/2
+	x1 := cosPhi*dx + sinPhi*dy
+	y1 := -sinPhi*dx + cosPhi*dy
+
+	// Scale up radii that are too small to span the end points
+	if lambda := x1*x1/(rx*rx) + y1*y1/(ry*ry); lambda > 1 {
+		rx *= math.Sqrt(lambda)
+		ry *= math.Sqrt(lambda)
+	}
+
+	// Step 2: compute (cx', cy')
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	coef := math.Sqrt(math.Max(0, num/den))
+	if largeArc == sweep {
+		coef = -coef
+	}
+	cx1 := coef * rx * y1 / ry
+	cy1 := -coef * ry * x1 / rx
+
+	// Step 3: compute the center (cx, cy)
+	cx := cosPhi*cx1 - sinPhi*cy1 + (start.X+end.X)/2
+	cy := sinPhi*cx1 + cosPhi*cy1 + (start.Y+end.Y)/2
+
+	// Step 4: compute the start angle and sweep
+	theta1 := math.Atan2((y1-cy1)/ry, (x1-cx1)/rx)
+	dTheta := math.Atan2((-y1-cy1)/ry, (-x1-cx1)/rx) - theta1
+	if sweep && dTheta < 0 {
+		dTheta += 2 * math.Pi
+	} else if !sweep && dTheta > 0 {
+		dTheta -= 2 * math.Pi
+	}
+
+	// Approximate each piece of at most 90 degrees with a cubic whose
+	// handles are k times the radius long
+	pieces := int(math.Ceil(math.Abs(dTheta) / (math.Pi / 2)))
+	delta := dTheta / float64(pieces)
+	k := 4.0 / 3 * math.Tan(delta/4)
+
+	onEllipse := func(theta float64) (Point, Point) {
+		sin, cos := math.Sin(theta), math.Cos(theta)
+		pt := Point{
+			X: cx + rx*cos*cosPhi - ry*sin*sinPhi,
+			Y: cy + rx*cos*sinPhi + ry*sin*cosPhi,
+		}
+		// Derivative with respect to theta
+		d := Point{
+			X: -rx*sin*cosPhi - ry*cos*sinPhi,
+			Y: -rx*sin*sinPhi + ry*cos*cosPhi,
+		}
+		return pt, d
+	}
+
+	theta := theta1
+	for i := 0; i < pieces; i++ {
+		p0, d0 := onEllipse(theta)
+		p3, d3 := onEllipse(theta + delta)
+		if i == 0 {
+			p0 = start
+		}
+		if i == pieces-1 {
+			p3 = end
+		}
+		p.segment(p0, vAdd(p0, Scale(d0, k)), vSub(p3, Scale(d3, k)), p3)
+		theta += delta
+	}
+}
+
+func (p *svgPathParser) point() (Point, error) {
+	x, err := p.number()
+	if err != nil {
+		return Point{}, err
+	}
+	y, err := p.number()
+	if err != nil {
+		return Point{}, err
+	}
+	return Point{X: x, Y: y}, nil
+}
+
+// number reads the next number, following the SVG grammar in which e.g.
+// "1.5.5-2" is the three numbers 1.5, .5 and -2.
+func (p *svgPathParser) number() (float64, error) {
+	p.skipSeparators()
+	start := p.pos
+	if p.pos < len(p.data) && (p.data[p.pos] == '+' || p.data[p.pos] == '-') {
+		p.pos++
+	}
+	digits := p.digits()
+	if p.pos < len(p.data) && p.data[p.pos] == '.' {
+		p.pos++
+		digits += p.digits()
+	}
+	if digits == 0 {
+		p.pos = start
+		return 0, p.errorf("expected a number")
+	}
+	if p.pos < len(p.data) && (p.data[p.pos] == 'e' || p.data[p.pos] == 'E') {
+		mark := p.pos
+		p.pos++
+		if p.pos < len(p.data) && (p.data[p.pos] == '+' || p.data[p.pos] == '-') {
+			p.pos++
+		}
+		if p.digits() == 0 {
+			// Not an exponent after all
+			p.pos = mark
+		}
+	}
+	return strconv.ParseFloat(p.data[start:p.pos], 64)
+}
+
+// flag reads an arc flag, which may be written without a separator after it.
+func (p *svgPathParser) flag() (bool, error) {
+	p.skipSeparators()
+	if p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case '0':
+			p.pos++
+			return false, nil
+		case '1':
+			p.pos++
+			return true, nil
+		}
+	}
+	return false, p.errorf("expected an arc flag (0 or 1)")
+}
+
+func (p *svgPathParser) digits() int {
+	n := 0
+	for p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+		p.pos++
+		n++
+	}
+	return n
+}
+
+func (p *svgPathParser) skipSeparators() {
+	for p.pos < len(p.data) && strings.IndexByte(" \t\r\n\f,", p.data[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *svgPathParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("svg path: offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
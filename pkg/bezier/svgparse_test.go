@@ -0,0 +1,206 @@
+package bezier
+
+import (
+	"math"
+	"testing"
+)
+
+// checkSubpaths fails the test unless got has the segments and closedness of
+// want, to within rounding.
+func checkSubpaths(t *testing.T, name string, got []SVGSubpath, want []SVGSubpath) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: %d subpaths, want %d", name, len(got), len(want))
+	}
+	for i := range got {
+		if got[i].Closed != want[i].Closed {
+			t.Errorf("%s: subpath %d closed is %v, want %v", name, i, got[i].Closed, want[i].Closed)
+		}
+		if len(got[i].Segments) != len(want[i].Segments) {
+			t.Fatalf("%s: subpath %d has %d segments, want %d", name, i, len(got[i].Segments), len(want[i].Segments))
+		}
+		for j, seg := range got[i].Segments {
+			wantPoints := want[i].Segments[j].Points
+			if len(seg.Points) != len(wantPoints) {
+				t.Fatalf("%s: segment %d of subpath %d is %v, want %v", name, j, i, seg.Points, wantPoints)
+			}
+			for k := range seg.Points {
+				if vDistance(seg.Points[k], wantPoints[k]) > 1e-9 {
+					t.Fatalf("%s: segment %d of subpath %d is %v, want %v", name, j, i, seg.Points, wantPoints)
+				}
+			}
+		}
+	}
+}
+
+// curves returns a subpath of the Bézier curves with the given points.
+func curves(closed bool, points ...[]Point) SVGSubpath {
+	s := SVGSubpath{Closed: closed}
+	for _, p := range points {
+		curve, _ := NewBezier(false, p...)
+		s.Segments = append(s.Segments, curve)
+	}
+	return s
+}
+
+func TestParseSVGPath(t *testing.T) {
+	tests := []struct {
+		name string
+		d    string
+		want []SVGSubpath
+	}{
+		{
+			name: "lines",
+			d:    "M10 10 L30 10 V20 H10 Z",
+			want: []SVGSubpath{curves(true,
+				lineSegment(Point{X: 10, Y: 10}, Point{X: 30, Y: 10}).Points,
+				lineSegment(Point{X: 30, Y: 10}, Point{X: 30, Y: 20}).Points,
+				lineSegment(Point{X: 30, Y: 20}, Point{X: 10, Y: 20}).Points,
+				lineSegment(Point{X: 10, Y: 20}, Point{X: 10, Y: 10}).Points,
+			)},
+		},
+		{
+			name: "implicit lines",
+			d:    "m10,10 20,0-20-10",
+			want: []SVGSubpath{curves(false,
+				lineSegment(Point{X: 10, Y: 10}, Point{X: 30, Y: 10}).Points,
+				lineSegment(Point{X: 30, Y: 10}, Point{X: 10, Y: 0}).Points,
+			)},
+		},
+		{
+			name: "smooth cubic",
+			d:    "M0 0 C10 0 20 10 20 20 S30 40 40 40",
+			want: []SVGSubpath{curves(false,
+				[]Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 20, Y: 10}, {X: 20, Y: 20}},
+				[]Point{{X: 20, Y: 20}, {X: 20, Y: 30}, {X: 30, Y: 40}, {X: 40, Y: 40}},
+			)},
+		},
+		{
+			name: "smooth cubic without a cubic before",
+			d:    "M0 0 S10 20 20 20",
+			want: []SVGSubpath{curves(false,
+				[]Point{{X: 0, Y: 0}, {X: 0, Y: 0}, {X: 10, Y: 20}, {X: 20, Y: 20}},
+			)},
+		},
+		{
+			name: "smooth quadratic",
+			d:    "M0 0 Q10 10 20 0 T40 0 T60 0",
+			want: []SVGSubpath{curves(false,
+				[]Point{{X: 0, Y: 0}, {X: 10, Y: 10}, {X: 20, Y: 0}},
+				[]Point{{X: 20, Y: 0}, {X: 30, Y: -10}, {X: 40, Y: 0}},
+				[]Point{{X: 40, Y: 0}, {X: 50, Y: 10}, {X: 60, Y: 0}},
+			)},
+		},
+		{
+			name: "new subpath after closing",
+			d:    "M0 0 h10 v10 z l0 10",
+			want: []SVGSubpath{
+				curves(true,
+					lineSegment(Point{X: 0, Y: 0}, Point{X: 10, Y: 0}).Points,
+					lineSegment(Point{X: 10, Y: 0}, Point{X: 10, Y: 10}).Points,
+					lineSegment(Point{X: 10, Y: 10}, Point{X: 0, Y: 0}).Points,
+				),
+				curves(false, lineSegment(Point{X: 0, Y: 0}, Point{X: 0, Y: 10}).Points),
+			},
+		},
+		{
+			name: "packed numbers",
+			d:    "M1.5.5L-2e1-.5e-1",
+			want: []SVGSubpath{curves(false,
+				lineSegment(Point{X: 1.5, Y: 0.5}, Point{X: -20, Y: -0.05}).Points,
+			)},
+		},
+	}
+	for _, tc := range tests {
+		got, err := ParseSVGPath(tc.d)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		checkSubpaths(t, tc.name, got, tc.want)
+	}
+}
+
+func TestParseSVGPathRelative(t *testing.T) {
+	tests := []struct {
+		absolute, relative string
+	}{
+		{"M10 10 L30 10 V20 H10 Z", "m10 10 l20 0 v10 h-20 z"},
+		{"M0 0 C10 0 20 10 20 20 S30 40 40 40", "m0 0 c10 0 20 10 20 20 s10 20 20 20"},
+		{"M0 0 Q10 10 20 0 T40 0", "m0 0 q10 10 20 0 t20 0"},
+		{"M10 0 A10 10 0 0 1 0 10", "m10 0 a10 10 0 0 1 -10 10"},
+		{"M0 0 L10 0 Z M20 20 L30 20", "m0 0 l10 0 z m20 20 l10 0"},
+	}
+	for _, tc := range tests {
+		want, err := ParseSVGPath(tc.absolute)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.absolute, err)
+		}
+		got, err := ParseSVGPath(tc.relative)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.relative, err)
+		}
+		checkSubpaths(t, tc.relative, got, want)
+	}
+}
+
+func TestParseSVGPathArcs(t *testing.T) {
+	tests := []struct {
+		name   string
+		d      string
+		center Point
+		radius float64
+		pieces int
+	}{
+		{name: "quarter", d: "M10 0 A10 10 0 0 1 0 10", center: Point{}, radius: 10, pieces: 1},
+		{name: "other way", d: "M10 0 A10 10 0 0 0 0 10", center: Point{X: 10, Y: 10}, radius: 10, pieces: 1},
+		{name: "large", d: "M10 0 A10 10 0 1 1 0 10", center: Point{X: 10, Y: 10}, radius: 10, pieces: 3},
+		{name: "half", d: "M-5 0 A5 5 0 0 1 5 0", center: Point{}, radius: 5, pieces: 2},
+		// Radii too small to reach are scaled up
+		{name: "too small", d: "M-5 0 A1 1 0 0 1 5 0", center: Point{}, radius: 5, pieces: 2},
+		{name: "relative", d: "M20 10 a10 10 0 1 0 -20 0 a10 10 0 1 0 20 0", center: Point{X: 10, Y: 10}, radius: 10, pieces: 4},
+	}
+	for _, tc := range tests {
+		subpaths, err := ParseSVGPath(tc.d)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		segments := subpaths[0].Segments
+		if len(segments) != tc.pieces {
+			t.Errorf("%s: %d pieces, want %d", tc.name, len(segments), tc.pieces)
+		}
+		for i, seg := range segments {
+			if i > 0 && vDistance(seg.Points[0], segments[i-1].Points[3]) != 0 {
+				t.Fatalf("%s: piece %d doesn't start where the last ended", tc.name, i)
+			}
+			// Cubics of up to a quarter turn stay within 0.03% of the radius
+			for u := 0.0; u <= 1; u += 0.125 {
+				if r := vDistance(seg.Get(u), tc.center); math.Abs(r-tc.radius) > 3e-4*tc.radius {
+					t.Fatalf("%s: piece %d at %g is %g from the center, want %g", tc.name, i, u, r, tc.radius)
+				}
+			}
+		}
+	}
+
+	// Arcs with a zero radius are lines, and those to the current point
+	// are nothing at all
+	subpaths, err := ParseSVGPath("M0 0 A0 10 0 0 1 10 0 A5 5 0 0 1 10 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSubpaths(t, "degenerate arcs", subpaths, []SVGSubpath{curves(false, lineSegment(Point{}, Point{X: 10}).Points)})
+}
+
+func TestParseSVGPathErrors(t *testing.T) {
+	for _, d := range []string{
+		"L10 10",
+		"M0 0 L10",
+		"M0 0 X10 10",
+		"M0 0 A1 1 0 2 0 1 1",
+		"M0 0 Z 10 10",
+		"M0 0 L1e 5",
+	} {
+		if _, err := ParseSVGPath(d); err == nil {
+			t.Errorf("parsed %q", d)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package bezier
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// arcLengthSteps is the number of intervals each segment is sampled at when
+// mapping arc length back to t.
+const arcLengthSteps = 100
+
+// SplineFrames walks a spline (in the 3n - 2 layout of CreateHobbySpline or
+// CreateHobbySpline3D) and returns a rotation-minimizing frame every spacing
+// units of arc length, plus one at the very end. Planar splines are treated as
+// lying in the XY plane.
+//
+// The frames are propagated across segment boundaries, so they stay free of
+// twist along the whole spline rather than restarting at each knot.
+func SplineFrames(spline []Point, spacing float64) ([]Frame, error) {
+	if spacing <= 0 {
+		return nil, errors.New("spacing must be positive")
+	}
+	curves, err := Segments3D(spline)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build a table of cumulative arc length against (segment, t)
+	type sample struct {
+		curve    int
+		t        float64
+		distance float64
+	}
+	table := []sample{{}}
+	prev := curves[0].Get(0)
+	for c, curve := range curves {
+		for i := 1; i <= arcLengthSteps; i++ {
+			t := float64(i) / arcLengthSteps
+			p := curve.Get(t)
+			d := table[len(table)-1].distance + vSub3(p, prev).Length3()
+			table = append(table, sample{curve: c, t: t, distance: d})
+			prev = p
+		}
+	}
+	total := table[len(table)-1].distance
+
+	frameAt := func(distance float64) (Point, Point) {
+		i := sort.Search(len(table), func(i int) bool { return table[i].distance >= distance })
+		if i == 0 {
+			return curves[0].Get(0), curves[0].tangent(0)
+		}
+		if i == len(table) {
+			i--
+		}
+		lo, hi := table[i-1], table[i]
+		t := hi.t
+		if span := hi.distance - lo.distance; span > 0 {
+			loT := lo.t
+			if lo.curve != hi.curve {
+				loT = 0
+			}
+			t = loT + (hi.t-loT)*(distance-lo.distance)/span
+		}
+		curve := curves[hi.curve]
+		return curve.Get(t), curve.tangent(t)
+	}
+
+	frames := []Frame{curves[0].Frenet(0)}
+	for s := spacing; ; s += spacing {
+		if s > total {
+			s = total
+		}
+		origin, tangent := frameAt(s)
+		frames = append(frames, nextFrame(frames[len(frames)-1], origin, tangent))
+		if s == total {
+			break
+		}
+	}
+	return frames, nil
+}
+
+// Mesh is an indexed triangle mesh. Every three entries of Indices form one
+// counter-clockwise triangle, indexing into Positions and Normals.
+type Mesh struct {
+	Positions []Point
+	Normals   []Point
+	Indices   []int
+}
+
+// CircleProfile returns a counter-clockwise circle of the given radius with
+// the given number of sides, for sweeping tubes.
+func CircleProfile(radius float64, sides int) []Point {
+	profile := make([]Point, sides)
+	for i := range profile {
+		angle := 2 * math.Pi * float64(i) / float64(sides)
+		profile[i] = Point{X: radius * math.Cos(angle), Y: radius * math.Sin(angle)}
+	}
+	return profile
+}
+
+// Sweep extrudes a 2D profile along a sequence of frames (such as those from
+// SplineFrames), producing a triangle mesh. Each profile point is placed at
+// X units along the frame's normal and Y units along its binormal.
+//
+// A closed profile is joined back to its first point, producing a tube. For
+// a counter-clockwise closed profile the triangles face outwards. An open
+// profile, e.g. a single line, produces a ribbon.
+func Sweep(frames []Frame, profile []Point, closed bool) (*Mesh, error) {
+	if len(frames) < 2 {
+		return nil, errors.New("at least 2 frames are required")
+	}
+	if len(profile) < 2 {
+		return nil, errors.New("at least 2 profile points are required")
+	}
+
+	m := len(profile)
+	mesh := &Mesh{}
+	for _, f := range frames {
+		for _, p := range profile {
+			mesh.Positions = append(mesh.Positions, vAdd3(f.Origin, vAdd3(Scale3(f.Normal, p.X), Scale3(f.Binormal, p.Y))))
+		}
+	}
+
+	edges := m - 1
+	if closed {
+		edges = m
+	}
+	for i := 0; i < len(frames)-1; i++ {
+		for j := 0; j < edges; j++ {
+			a := i*m + j
+			b := i*m + (j+1)%m
+			c := (i+1)*m + (j+1)%m
+			d := (i+1)*m + j
+			mesh.Indices = append(mesh.Indices, a, b, c, a, c, d)
+		}
+	}
+
+	// Vertex normals are the area-weighted average of the normals of the
+	// faces around them
+	mesh.Normals = make([]Point, len(mesh.Positions))
+	for i := 0; i < len(mesh.Indices); i += 3 {
+		a, b, c := mesh.Indices[i], mesh.Indices[i+1], mesh.Indices[i+2]
+		n := vCross(vSub3(mesh.Positions[b], mesh.Positions[a]), vSub3(mesh.Positions[c], mesh.Positions[a]))
+		for _, v := range []int{a, b, c} {
+			mesh.Normals[v] = vAdd3(mesh.Normals[v], n)
+		}
+	}
+	for i, n := range mesh.Normals {
+		if l := n.Length3(); l != 0 {
+			mesh.Normals[i] = Scale3(n, 1/l)
+		}
+	}
+
+	return mesh, nil
+}
+
+// WriteOBJ writes the mesh in Wavefront OBJ format.
+func (m *Mesh) WriteOBJ(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, p := range m.Positions {
+		fmt.Fprintf(bw, "v %g %g %g\n", p.X, p.Y, p.Z)
+	}
+	for _, n := range m.Normals {
+		fmt.Fprintf(bw, "vn %g %g %g\n", n.X, n.Y, n.Z)
+	}
+	// OBJ indices are 1-based
+	for i := 0; i+2 < len(m.Indices); i += 3 {
+		a, b, c := m.Indices[i]+1, m.Indices[i+1]+1, m.Indices[i+2]+1
+		fmt.Fprintf(bw, "f %d//%d %d//%d %d//%d\n", a, a, b, b, c, c)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,141 @@
+package bezier
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSweepCounts(t *testing.T) {
+	frames, err := SplineFrames(helix(1, 0.5, 8), 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A frame every 0.1 along the helix, plus one at each end
+	length := 2 * math.Pi * math.Hypot(1, 0.5)
+	if want := int(math.Ceil(length/0.1)) + 1; len(frames) < want-1 || len(frames) > want+1 {
+		t.Fatalf("%d frames along a helix %g long, want about %d", len(frames), length, want)
+	}
+	f := len(frames)
+
+	tests := []struct {
+		name    string
+		profile []Point
+		closed  bool
+		// faces is the number of triangles between successive frames
+		faces int
+	}{
+		{name: "tube", profile: CircleProfile(0.1, 8), closed: true, faces: 16},
+		{name: "open circle", profile: CircleProfile(0.1, 8), faces: 14},
+		{name: "ribbon", profile: []Point{{X: -0.2}, {X: 0.2}}, faces: 2},
+	}
+	for _, tc := range tests {
+		mesh, err := Sweep(frames, tc.profile, tc.closed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(mesh.Positions), f*len(tc.profile); got != want {
+			t.Errorf("%s: %d vertices, want %d", tc.name, got, want)
+		}
+		if len(mesh.Normals) != len(mesh.Positions) {
+			t.Errorf("%s: %d normals for %d vertices", tc.name, len(mesh.Normals), len(mesh.Positions))
+		}
+		if got, want := len(mesh.Indices), 3*tc.faces*(f-1); got != want {
+			t.Errorf("%s: %d indices, want %d", tc.name, got, want)
+		}
+		for _, i := range mesh.Indices {
+			if i < 0 || i >= len(mesh.Positions) {
+				t.Fatalf("%s: index %d out of range", tc.name, i)
+			}
+		}
+	}
+}
+
+func TestSweepTube(t *testing.T) {
+	frames, err := SplineFrames(helix(1, 0.5, 8), 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const radius, sides = 0.1, 12
+	mesh, err := Sweep(frames, CircleProfile(radius, sides), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range mesh.Positions {
+		f := frames[i/sides]
+		out := vSub3(p, f.Origin)
+		if math.Abs(out.Length3()-radius) > 1e-9 || math.Abs(vDot3(out, f.Tangent)) > 1e-9 {
+			t.Fatalf("vertex %d is %v from its frame's origin, want %g across the tangent", i, out, radius)
+		}
+		// The tube's faces point outwards
+		if n := mesh.Normals[i]; math.Abs(n.Length3()-1) > 1e-9 || vDot3(n, out) <= 0 {
+			t.Fatalf("vertex %d has normal %v, pointing into the tube", i, n)
+		}
+	}
+}
+
+func TestMeshWriteOBJ(t *testing.T) {
+	frames, err := SplineFrames(helix(1, 0.5, 4), 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mesh, err := Sweep(frames, CircleProfile(0.1, 4), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := mesh.WriteOBJ(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	counts := map[string]int{}
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		counts[fields[0]]++
+		switch fields[0] {
+		case "v", "vn":
+			var x, y, z float64
+			if _, err := fmt.Sscanf(sc.Text(), fields[0]+" %g %g %g", &x, &y, &z); err != nil {
+				t.Fatalf("bad line %q: %v", sc.Text(), err)
+			}
+		case "f":
+			if len(fields) != 4 {
+				t.Fatalf("face %q isn't a triangle", sc.Text())
+			}
+			for _, corner := range fields[1:] {
+				var v, n int
+				if _, err := fmt.Sscanf(corner, "%d//%d", &v, &n); err != nil {
+					t.Fatalf("bad corner %q: %v", corner, err)
+				}
+				if v != n || v < 1 || v > len(mesh.Positions) {
+					t.Fatalf("corner %q doesn't index a vertex and its normal", corner)
+				}
+			}
+		default:
+			t.Fatalf("unexpected line %q", sc.Text())
+		}
+	}
+	if counts["v"] != len(mesh.Positions) || counts["vn"] != len(mesh.Normals) || counts["f"] != len(mesh.Indices)/3 {
+		t.Fatalf("wrote %v for %d vertices and %d triangles", counts, len(mesh.Positions), len(mesh.Indices)/3)
+	}
+}
+
+func TestSweepErrors(t *testing.T) {
+	frames, err := SplineFrames(helix(1, 0.5, 4), 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SplineFrames(helix(1, 0.5, 4), 0); err == nil {
+		t.Error("made frames 0 apart")
+	}
+	if _, err := Sweep(frames[:1], CircleProfile(1, 4), true); err == nil {
+		t.Error("swept along a single frame")
+	}
+	if _, err := Sweep(frames, []Point{{X: 1}}, false); err == nil {
+		t.Error("swept a single point")
+	}
+}
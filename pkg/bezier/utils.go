@@ -106,9 +106,10 @@ func compute(t float64, points []Point, use3d bool) Point {
 	// linear?
 	if order == 1 {
 		result := Point{
-			X: mt*p[0].X + t*p[1].X,
-			Y: mt*p[0].Y + t*p[1].Y,
-			t: t,
+			X:                mt*p[0].X + t*p[1].X,
+			Y:                mt*p[0].Y + t*p[1].Y,
+			t:                t,
+			threeDimensional: use3d,
 		}
 		if use3d {
 			result.Z = mt*p[0].Z + t*p[1].Z
@@ -133,9 +134,10 @@ func compute(t float64, points []Point, use3d bool) Point {
 			d = t * t2
 		}
 		result := Point{
-			X: a*p[0].X + b*p[1].X + c*p[2].X + d*p[3].X,
-			Y: a*p[0].Y + b*p[1].Y + c*p[2].Y + d*p[3].Y,
-			t: t,
+			X:                a*p[0].X + b*p[1].X + c*p[2].X + d*p[3].X,
+			Y:                a*p[0].Y + b*p[1].Y + c*p[2].Y + d*p[3].Y,
+			t:                t,
+			threeDimensional: use3d,
 		}
 		if use3d {
 			result.Z = a*p[0].Z + b*p[1].Z + c*p[2].Z + d*p[3].Z
@@ -152,7 +154,7 @@ func compute(t float64, points []Point, use3d bool) Point {
 			dCpts[i].X = dCpts[i].X + (dCpts[i+1].X-dCpts[i].X)*t
 			dCpts[i].Y = dCpts[i].Y + (dCpts[i+1].Y-dCpts[i].Y)*t
 
-			if dCpts[i].threeDimensional {
+			if use3d {
 				dCpts[i].Z = dCpts[i].Z + (dCpts[i+1].Z-dCpts[i].Z)*t
 			}
 		}
@@ -162,6 +164,42 @@ func compute(t float64, points []Point, use3d bool) Point {
 	return dCpts[0]
 }
 
+// projectSteps is the number of intervals sampled for the coarse pass of a
+// projection, before it is refined around the closest sample.
+const projectSteps = 100
+
+func project(p Point, points []Point, use3d bool) (Point, float64) {
+	distSq := func(q Point) float64 {
+		dx, dy, dz := q.X-p.X, q.Y-p.Y, q.Z-p.Z
+		return dx*dx + dy*dy + dz*dz
+	}
+
+	// Find the closest point on a coarse lookup table first...
+	bestT := 0.0
+	best := compute(0, points, use3d)
+	bestDist := distSq(best)
+	for i := 1; i <= projectSteps; i++ {
+		t := float64(i) / projectSteps
+		q := compute(t, points, use3d)
+		if d := distSq(q); d < bestDist {
+			best, bestT, bestDist = q, t, d
+		}
+	}
+
+	// ...then refine the parameter within the neighbouring intervals
+	step := 1.0 / projectSteps
+	lo, hi := math.Max(0, bestT-step), math.Min(1, bestT+step)
+	for i := 0; i <= projectSteps; i++ {
+		t := lo + (hi-lo)*float64(i)/projectSteps
+		q := compute(t, points, use3d)
+		if d := distSq(q); d < bestDist {
+			best, bestT, bestDist = q, t, d
+		}
+	}
+
+	return best, bestT
+}
+
 func normal2(t float64, df DerivativeFunc) Point {
 	d := df(t)
 	q := math.Sqrt(d.X*d.X + d.Y*d.Y)
@@ -171,50 +209,6 @@ func normal2(t float64, df DerivativeFunc) Point {
 		t: t,
 	}
 }
-func normal3(t float64, df DerivativeFunc) Point {
-	// see http://stackoverflow.com/questions/25453159
-	r1 := df(t)
-	r2 := df(t + 0.01)
-	q1 := math.Sqrt(r1.X*r1.X + r1.Y*r1.Y + r1.Z*r1.Z)
-	q2 := math.Sqrt(r2.X*r2.X + r2.Y*r2.Y + r2.Z*r2.Z)
-
-	r1.X /= q1
-	r1.Y /= q1
-	r1.Z /= q1
-	r2.X /= q2
-	r2.Y /= q2
-	r2.Z /= q2
-	// cross product
-	c := Point{
-		X: r2.Y*r1.Z - r2.Z*r1.Y,
-		Y: r2.Z*r1.X - r2.X*r1.Z,
-		Z: r2.X*r1.Y - r2.Y*r1.X,
-	}
-	m := math.Sqrt(c.X*c.X + c.Y*c.Y + c.Z*c.Z)
-	c.X /= m
-	c.Y /= m
-	c.Z /= m
-	// rotation matrix
-	R := []float64{
-		c.X * c.X,
-		c.X*c.Y - c.Z,
-		c.X*c.Z + c.Y,
-		c.X*c.Y + c.Z,
-		c.Y * c.Y,
-		c.Y*c.Z - c.X,
-		c.X*c.Z - c.Y,
-		c.Y*c.Z + c.X,
-		c.Z * c.Z,
-	}
-	// normal vector
-	return Point{
-		t: t,
-		X: R[0]*r1.X + R[1]*r1.Y + R[2]*r1.Z,
-		Y: R[3]*r1.X + R[4]*r1.Y + R[5]*r1.Z,
-		Z: R[6]*r1.X + R[7]*r1.Y + R[8]*r1.Z,
-	}
-}
-
 func curvature(t float64, d1 []Point, d2 []Point, use3d bool, kOnly bool) CurvatureVector {
 	/*
 		We're using the following formula for curvature:
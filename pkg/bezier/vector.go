@@ -18,6 +18,10 @@ func Scale(v Point, s float64) Point {
 	return Point{X: v.X * s, Y: v.Y * s}
 }
 
+func vDot(a Point, b Point) float64 {
+	return a.X*b.X + a.Y*b.Y
+}
+
 func vDistance(from Point, to Point) float64 {
 	return vSub(to, from).Length()
 }
@@ -46,3 +50,48 @@ func Normalize(v Point) Point {
 		Y: v.Y / l,
 	}
 }
+
+// The helpers above work in the XY plane and drop Z. The ones below are their
+// three-dimensional counterparts, used for space curves.
+
+func (v Point) Length3() float64 {
+	return math.Sqrt(v.X*v.X + v.Y*v.Y + v.Z*v.Z)
+}
+
+func vAdd3(a Point, b Point) Point {
+	return Point{X: a.X + b.X, Y: a.Y + b.Y, Z: a.Z + b.Z}
+}
+
+func vSub3(a Point, b Point) Point {
+	return Point{X: a.X - b.X, Y: a.Y - b.Y, Z: a.Z - b.Z}
+}
+
+func Scale3(v Point, s float64) Point {
+	return Point{X: v.X * s, Y: v.Y * s, Z: v.Z * s}
+}
+
+func vDot3(a Point, b Point) float64 {
+	return a.X*b.X + a.Y*b.Y + a.Z*b.Z
+}
+
+func vCross(a Point, b Point) Point {
+	return Point{
+		X: a.Y*b.Z - a.Z*b.Y,
+		Y: a.Z*b.X - a.X*b.Z,
+		Z: a.X*b.Y - a.Y*b.X,
+	}
+}
+
+func Normalize3(v Point) Point {
+	return Scale3(v, 1/v.Length3())
+}
+
+// RotateAround rotates v by angle radians around the unit vector axis, using
+// Rodrigues' rotation formula.
+func RotateAround(v Point, axis Point, angle float64) Point {
+	cos, sin := math.Cos(angle), math.Sin(angle)
+	return vAdd3(
+		vAdd3(Scale3(v, cos), Scale3(vCross(axis, v), sin)),
+		Scale3(axis, vDot3(axis, v)*(1-cos)),
+	)
+}
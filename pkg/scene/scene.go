@@ -0,0 +1,340 @@
+// Package scene defines the file format the demo saves its state in: the
+// paths in it, the knots of each and the constraints on them, how each path
+// is fitted to its knots, and what is shown alongside them.
+//
+// Scenes are stored as JSON, for example:
+//
+//	{
+//	  "version": 2,
+//	  "paths": [
+//	    {
+//	      "algorithm": "metapost",
+//	      "omega": 0.75,
+//	      "color": "#8839ef",
+//	      "points": [
+//	        {"x": 100, "y": 200},
+//	        {"x": 300, "y": 100, "direction": 0, "tension": 1.5, "out": {"x": 350, "y": 100}},
+//	        {"x": 500, "y": 200, "in": {"x": 450, "y": 150}}
+//	      ]
+//	    },
+//	    {"closed": true, "points": [{"x": 250, "y": 250}, {"x": 300, "y": 300}, {"x": 200, "y": 300}]}
+//	  ],
+//	  "view": {"showComb": true, "showNatural": false, "showHandles": true}
+//	}
+//
+// Version 1 scenes had a single path, whose fields were at the top level
+// instead of in "paths". Read still accepts them.
+package scene
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+
+	"github.com/braheezy/hobby-spline/pkg/bezier"
+)
+
+// Version is the newest version of the format, and the one Write produces.
+const Version = 2
+
+// Algorithms that can be used to fit the curve to the knots.
+const (
+	// AlgorithmHobby uses bezier.CreateHobbySpline, or
+	// bezier.CreateClosedHobbySpline for closed curves.
+	AlgorithmHobby = "hobby"
+	// AlgorithmNatural uses bezier.NaturalCubicSpline.
+	AlgorithmNatural = "natural"
+	// AlgorithmMetaPost uses bezier.Path, and is the only algorithm that
+	// honours the constraints on knots.
+	AlgorithmMetaPost = "metapost"
+)
+
+// Scene is everything needed to recreate what the demo shows.
+type Scene struct {
+	Version int    `json:"version"`
+	Paths   []Path `json:"paths"`
+	View    View   `json:"view"`
+}
+
+// Path is a curve through knots, fitted to them independently of the other
+// paths in the scene.
+type Path struct {
+	Algorithm string  `json:"algorithm"`
+	Omega     float64 `json:"omega"`
+	Closed    bool    `json:"closed"`
+	Points    []Knot  `json:"points"`
+	// Color is the color the path is drawn in, as "#rrggbb", or empty to
+	// leave the choice to whatever draws it.
+	Color string `json:"color,omitempty"`
+}
+
+// Knot is a point the curve passes through, with optional constraints on how
+// it does so.
+type Knot struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+
+	// Direction fixes the direction of the curve at the knot, in degrees
+	// counter-clockwise from the X axis in the same coordinates as the
+	// points. Since Y points down on screen, that appears clockwise there.
+	Direction *float64 `json:"direction,omitempty"`
+	// Curl fixes the curl at the knot, like omega does at the ends of the
+	// curve. It can't be combined with Direction.
+	Curl *float64 `json:"curl,omitempty"`
+	// Tension is the tension of the segments either side of the knot. Higher
+	// values make them tighter, and the default is 1.
+	Tension *float64 `json:"tension,omitempty"`
+
+	// In and Out override the control points of the segments arriving at
+	// and leaving the knot, whatever the algorithm. They come in pairs: if
+	// a knot has an Out handle, the next knot must have an In handle.
+	In  *Handle `json:"in,omitempty"`
+	Out *Handle `json:"out,omitempty"`
+}
+
+// Handle is the position of an explicit control point.
+type Handle struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Equal reports whether two knots are at the same place with the same
+// constraints and handles.
+func (k Knot) Equal(o Knot) bool {
+	return k.X == o.X && k.Y == o.Y &&
+		equalPtr(k.Direction, o.Direction) && equalPtr(k.Curl, o.Curl) && equalPtr(k.Tension, o.Tension) &&
+		equalPtr(k.In, o.In) && equalPtr(k.Out, o.Out)
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// View holds the display settings of the demo.
+type View struct {
+	ShowComb    bool `json:"showComb"`
+	ShowNatural bool `json:"showNatural"`
+	ShowHandles bool `json:"showHandles"`
+}
+
+// Default returns the scene the demo starts with.
+func Default() *Scene {
+	path := DefaultPath()
+	path.Points = []Knot{
+		{X: 356, Y: 229},
+		{X: 523, Y: 287},
+		{X: 505, Y: 72},
+		{X: 109, Y: 224},
+		{X: 108, Y: 92},
+		{X: 232, Y: 307},
+	}
+	return &Scene{
+		Version: Version,
+		Paths:   []Path{path},
+		View: View{
+			ShowComb:    true,
+			ShowNatural: true,
+		},
+	}
+}
+
+// DefaultPath returns a path without knots, with the settings paths take
+// when they're missing from a scene.
+func DefaultPath() Path {
+	return Path{Algorithm: AlgorithmHobby, Omega: 0.75}
+}
+
+// Read reads a scene, checking it against the format first and migrating it
+// from older versions. Fields that are missing take their value from
+// Default and DefaultPath, except for the version, the paths and their
+// points, which are required.
+//
+// If the scene doesn't match the format, the error is a *ValidationError
+// naming the offending field.
+func Read(r io.Reader) (*Scene, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := Validate(data); err != nil {
+		return nil, err
+	}
+
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+	if header.Version == 1 {
+		return migrateV1(data)
+	}
+
+	doc := struct {
+		Paths []json.RawMessage `json:"paths"`
+		View  View              `json:"view"`
+	}{View: Default().View}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	s := &Scene{Version: Version, View: doc.View}
+	for _, raw := range doc.Paths {
+		path := DefaultPath()
+		if err := json.Unmarshal(raw, &path); err != nil {
+			return nil, err
+		}
+		s.Paths = append(s.Paths, path)
+	}
+	return s, nil
+}
+
+// migrateV1 reads a scene in version 1 of the format, which had a single
+// path whose fields were at the top level.
+func migrateV1(data []byte) (*Scene, error) {
+	doc := struct {
+		Path
+		View View `json:"view"`
+	}{Path: DefaultPath(), View: Default().View}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return &Scene{Version: Version, Paths: []Path{doc.Path}, View: doc.View}, nil
+}
+
+// Write writes the scene as indented JSON in the current version of the
+// format.
+func Write(w io.Writer, s *Scene) error {
+	out := *s
+	out.Version = Version
+	data, err := json.MarshalIndent(&out, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// Marshal returns the scene as Write would write it.
+func Marshal(s *Scene) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := Write(&buf, s); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Points returns the positions of the knots.
+func Points(knots []Knot) []bezier.Point {
+	points := make([]bezier.Point, len(knots))
+	for i, k := range knots {
+		points[i] = bezier.Point{X: k.X, Y: k.Y}
+	}
+	return points
+}
+
+// Spline fits a spline to the knots of the path with its algorithm, in the
+// layout of bezier.CreateHobbySpline. Explicit handles replace the control
+// points the algorithm chose.
+func (p *Path) Spline() ([]bezier.Point, error) {
+	points := Points(p.Points)
+	var spline []bezier.Point
+	var err error
+	switch {
+	case p.Algorithm == AlgorithmNatural:
+		spline, err = bezier.NaturalCubicSpline(points)
+	case p.Algorithm == AlgorithmMetaPost:
+		// MetaPost takes explicit control points into account when
+		// choosing the rest
+		return p.BezierPath().Solve()
+	case p.Closed:
+		spline, err = bezier.CreateClosedHobbySpline(points)
+	default:
+		spline, err = bezier.CreateHobbySpline(points, p.Omega)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(p.Points)
+	for i := 0; 3*i+2 < len(spline); i++ {
+		out, in := p.Points[i].Out, p.Points[(i+1)%n].In
+		if out != nil && in != nil {
+			spline[3*i+1] = bezier.Point{X: out.X, Y: out.Y}
+			spline[3*i+2] = bezier.Point{X: in.X, Y: in.Y}
+		}
+	}
+	return spline, nil
+}
+
+// BezierPath returns the knots of the path and their constraints as a
+// bezier.Path. The ends of an open path are curled by omega unless they have
+// constraints of their own.
+func (p *Path) BezierPath() *bezier.Path {
+	path := &bezier.Path{Cycle: p.Closed}
+	for _, k := range p.Points {
+		knot := bezier.Knot{Point: bezier.Point{X: k.X, Y: k.Y}}
+		if k.Direction != nil {
+			angle := *k.Direction * math.Pi / 180
+			knot.LeftType, knot.LeftAngle = bezier.JoinGiven, angle
+			knot.RightType, knot.RightAngle = bezier.JoinGiven, angle
+		} else if k.Curl != nil {
+			knot.LeftType, knot.LeftCurl = bezier.JoinCurl, *k.Curl
+			knot.RightType, knot.RightCurl = bezier.JoinCurl, *k.Curl
+		}
+		if k.Tension != nil {
+			knot.LeftTension, knot.RightTension = *k.Tension, *k.Tension
+		}
+		if k.In != nil {
+			knot.LeftType, knot.LeftControl = bezier.JoinExplicit, bezier.Point{X: k.In.X, Y: k.In.Y}
+		}
+		if k.Out != nil {
+			knot.RightType, knot.RightControl = bezier.JoinExplicit, bezier.Point{X: k.Out.X, Y: k.Out.Y}
+		}
+		path.Knots = append(path.Knots, knot)
+	}
+
+	if n := len(path.Knots); !p.Closed && n > 0 {
+		if path.Knots[0].RightType == bezier.JoinOpen {
+			path.Knots[0].RightType, path.Knots[0].RightCurl = bezier.JoinCurl, p.Omega
+		}
+		if path.Knots[n-1].LeftType == bezier.JoinOpen {
+			path.Knots[n-1].LeftType, path.Knots[n-1].LeftCurl = bezier.JoinCurl, p.Omega
+		}
+	}
+	return path
+}
+
+// Knots returns unconstrained knots at the given points.
+func Knots(points []bezier.Point) []Knot {
+	knots := make([]Knot, len(points))
+	for i, p := range points {
+		knots[i] = Knot{X: p.X, Y: p.Y}
+	}
+	return knots
+}
+
+// ParseColor parses a color in the "#rrggbb" form used by Path.Color.
+func ParseColor(s string) (color.RGBA, error) {
+	var c color.RGBA
+	if len(s) != 7 {
+		return c, fmt.Errorf("color %q isn't of the form #rrggbb", s)
+	}
+	if _, err := fmt.Sscanf(s, "#%02x%02x%02x", &c.R, &c.G, &c.B); err != nil {
+		return c, fmt.Errorf("color %q isn't of the form #rrggbb", s)
+	}
+	c.A = 0xff
+	return c, nil
+}
+
+// FormatColor formats c in the "#rrggbb" form used by Path.Color, ignoring
+// its alpha.
+func FormatColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
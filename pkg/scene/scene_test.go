@@ -0,0 +1,129 @@
+package scene
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestMigrateV1(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want *Scene
+	}{
+		{
+			name: "defaults",
+			data: `{"version": 1, "points": [{"x": 1, "y": 2}, {"x": 3, "y": 4}]}`,
+			want: &Scene{
+				Version: Version,
+				Paths:   []Path{{Algorithm: AlgorithmHobby, Omega: 0.75, Points: []Knot{{X: 1, Y: 2}, {X: 3, Y: 4}}}},
+				View:    Default().View,
+			},
+		},
+		{
+			name: "every field",
+			data: `{
+				"version": 1,
+				"algorithm": "metapost",
+				"omega": 0.5,
+				"closed": true,
+				"points": [{"x": 1, "y": 2, "direction": 90, "tension": 2}, {"x": 3, "y": 4, "curl": 0}, {"x": 5, "y": 0}],
+				"view": {"showComb": false, "showHandles": true}
+			}`,
+			want: &Scene{
+				Version: Version,
+				Paths: []Path{{
+					Algorithm: AlgorithmMetaPost,
+					Omega:     0.5,
+					Closed:    true,
+					Points: []Knot{
+						{X: 1, Y: 2, Direction: ptr(90.0), Tension: ptr(2.0)},
+						{X: 3, Y: 4, Curl: ptr(0.0)},
+						{X: 5, Y: 0},
+					},
+				}},
+				// Settings that are missing keep their defaults
+				View: View{ShowComb: false, ShowNatural: true, ShowHandles: true},
+			},
+		},
+	}
+	for _, tc := range tests {
+		got, err := Read(strings.NewReader(tc.data))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: read %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	tests := []struct {
+		name  string
+		scene *Scene
+	}{
+		{name: "default", scene: Default()},
+		{
+			name: "constraints and handles",
+			scene: &Scene{
+				Version: Version,
+				Paths: []Path{
+					{
+						Algorithm: AlgorithmMetaPost,
+						Omega:     1,
+						Color:     "#8839ef",
+						Points: []Knot{
+							{X: 100, Y: 200, Curl: ptr(0.0)},
+							{X: 300, Y: 100, Direction: ptr(-45.5), Tension: ptr(1.5), Out: &Handle{X: 350, Y: 100}},
+							{X: 500, Y: 200, In: &Handle{X: 450, Y: 150}},
+						},
+					},
+					{
+						Algorithm: AlgorithmHobby,
+						Closed:    true,
+						Points:    []Knot{{X: 250, Y: 250}, {X: 300, Y: 300}, {X: 200, Y: 300}},
+					},
+				},
+				View: View{ShowHandles: true},
+			},
+		},
+		{
+			name: "natural",
+			scene: &Scene{
+				Version: Version,
+				Paths:   []Path{{Algorithm: AlgorithmNatural, Omega: 0.75, Points: Knots(Points(Default().Paths[0].Points))}},
+				View:    View{ShowComb: true, ShowNatural: true, ShowHandles: true},
+			},
+		},
+	}
+	for _, tc := range tests {
+		var buf bytes.Buffer
+		if err := Write(&buf, tc.scene); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		got, err := Read(&buf)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(got, tc.scene) {
+			t.Errorf("%s: read back %+v, want %+v", tc.name, got, tc.scene)
+		}
+	}
+
+	// Writing always uses the current version
+	old := Default()
+	old.Version = 1
+	data, err := Marshal(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte(fmt.Sprintf("{\n  \"version\": %d,", Version))) {
+		t.Errorf("wrote an old scene as:\n%s", data)
+	}
+}
@@ -0,0 +1,308 @@
+package scene
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ValidationError reports a scene that doesn't match the format.
+type ValidationError struct {
+	// Field is the path to the offending value, e.g. "paths[0].points[2].tension",
+	// or empty if the problem is with the document as a whole.
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return "scene: " + e.Message
+	}
+	return "scene: " + e.Field + ": " + e.Message
+}
+
+// Validate checks that data is a scene in a supported version of the format.
+// The first problem found is returned as a *ValidationError.
+func Validate(data []byte) error {
+	var doc any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return &ValidationError{Message: "invalid JSON: " + err.Error()}
+	}
+	if dec.More() {
+		return &ValidationError{Message: "unexpected data after the scene"}
+	}
+
+	root, ok := doc.(map[string]any)
+	if !ok {
+		return &ValidationError{Message: "must be an object"}
+	}
+	if _, ok := root["version"]; !ok {
+		return &ValidationError{Field: "version", Message: "missing"}
+	}
+	version, err := number(root["version"], "version")
+	if err != nil {
+		return err
+	}
+	if version != math.Trunc(version) || version < 1 {
+		return &ValidationError{Field: "version", Message: "must be a positive integer"}
+	}
+	if version > Version {
+		return &ValidationError{Field: "version", Message: fmt.Sprintf("%v is newer than the newest supported version, %d", version, Version)}
+	}
+
+	if version == 1 {
+		// The only path's fields were at the top level
+		if _, err := object(doc, "", "version", "algorithm", "omega", "closed", "points", "view"); err != nil {
+			return err
+		}
+		if err := validatePath(root, ""); err != nil {
+			return err
+		}
+	} else {
+		if _, err := object(doc, "", "version", "paths", "view"); err != nil {
+			return err
+		}
+		if _, ok := root["paths"]; !ok {
+			return &ValidationError{Field: "paths", Message: "missing"}
+		}
+		paths, isArray := root["paths"].([]any)
+		if !isArray {
+			return &ValidationError{Field: "paths", Message: "must be an array"}
+		}
+		if len(paths) == 0 {
+			return &ValidationError{Field: "paths", Message: "needs at least 1 path"}
+		}
+		for i, v := range paths {
+			field := fmt.Sprintf("paths[%d]", i)
+			path, err := object(v, field, "algorithm", "omega", "closed", "points", "color")
+			if err != nil {
+				return err
+			}
+			if err := validatePath(path, field+"."); err != nil {
+				return err
+			}
+		}
+	}
+
+	if v, ok := root["view"]; ok {
+		view, err := object(v, "view", "showComb", "showNatural", "showHandles")
+		if err != nil {
+			return err
+		}
+		for _, key := range sortedKeys(view) {
+			if _, isBool := view[key].(bool); !isBool {
+				return &ValidationError{Field: "view." + key, Message: "must be true or false"}
+			}
+		}
+	}
+
+	return nil
+}
+
+// validatePath checks the fields of a path, whose names are prefixed with
+// prefix in errors.
+func validatePath(path map[string]any, prefix string) error {
+	algorithm := AlgorithmHobby
+	if v, ok := path["algorithm"]; ok {
+		s, isString := v.(string)
+		if !isString || (s != AlgorithmHobby && s != AlgorithmNatural && s != AlgorithmMetaPost) {
+			return &ValidationError{Field: prefix + "algorithm", Message: fmt.Sprintf("must be %q, %q or %q", AlgorithmHobby, AlgorithmNatural, AlgorithmMetaPost)}
+		}
+		algorithm = s
+	}
+
+	if v, ok := path["omega"]; ok {
+		omega, err := number(v, prefix+"omega")
+		if err != nil {
+			return err
+		}
+		if omega < 0 || omega > 1 {
+			return &ValidationError{Field: prefix + "omega", Message: "must be between 0 and 1"}
+		}
+	}
+
+	closed := false
+	if v, ok := path["closed"]; ok {
+		b, isBool := v.(bool)
+		if !isBool {
+			return &ValidationError{Field: prefix + "closed", Message: "must be true or false"}
+		}
+		closed = b
+	}
+	if closed && algorithm == AlgorithmNatural {
+		return &ValidationError{Field: prefix + "closed", Message: "closed curves aren't supported by the natural algorithm"}
+	}
+
+	if v, ok := path["color"]; ok {
+		s, isString := v.(string)
+		if !isString {
+			return &ValidationError{Field: prefix + "color", Message: "must be a string"}
+		}
+		if _, err := ParseColor(s); err != nil {
+			return &ValidationError{Field: prefix + "color", Message: "must be of the form #rrggbb"}
+		}
+	}
+
+	if _, ok := path["points"]; !ok {
+		return &ValidationError{Field: prefix + "points", Message: "missing"}
+	}
+	points, isArray := path["points"].([]any)
+	if !isArray {
+		return &ValidationError{Field: prefix + "points", Message: "must be an array"}
+	}
+	minPoints := 2
+	if closed || algorithm == AlgorithmNatural {
+		minPoints = 3
+	}
+	if len(points) < minPoints {
+		return &ValidationError{Field: prefix + "points", Message: fmt.Sprintf("needs at least %d points", minPoints)}
+	}
+	for i, p := range points {
+		if err := validateKnot(p, fmt.Sprintf("%spoints[%d]", prefix, i), algorithm); err != nil {
+			return err
+		}
+	}
+	return validateHandlePairs(points, closed, prefix)
+}
+
+// validateKnot checks a single element of the points array.
+func validateKnot(v any, field string, algorithm string) error {
+	knot, err := object(v, field, "x", "y", "direction", "curl", "tension", "in", "out")
+	if err != nil {
+		return err
+	}
+	if err := coordinates(knot, field); err != nil {
+		return err
+	}
+	for _, key := range []string{"in", "out"} {
+		if h, ok := knot[key]; ok {
+			handle, err := object(h, field+"."+key, "x", "y")
+			if err != nil {
+				return err
+			}
+			if err := coordinates(handle, field+"."+key); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, key := range []string{"direction", "curl", "tension"} {
+		value, ok := knot[key]
+		if !ok {
+			continue
+		}
+		if algorithm != AlgorithmMetaPost {
+			return &ValidationError{Field: field + "." + key, Message: fmt.Sprintf("constraints are only supported by the %q algorithm", AlgorithmMetaPost)}
+		}
+		n, err := number(value, field+"."+key)
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "curl":
+			if _, hasDirection := knot["direction"]; hasDirection {
+				return &ValidationError{Field: field + ".curl", Message: "can't be combined with direction"}
+			}
+			if n < 0 {
+				return &ValidationError{Field: field + ".curl", Message: "must not be negative"}
+			}
+		case "tension":
+			// Like MetaPost, which refuses tensions below 3/4
+			if n < 0.75 {
+				return &ValidationError{Field: field + ".tension", Message: "must be at least 0.75"}
+			}
+		}
+	}
+	return nil
+}
+
+// validateHandlePairs checks that every explicit handle leaving a knot is
+// matched by one arriving at the next, and vice versa.
+func validateHandlePairs(points []any, closed bool, prefix string) error {
+	n := len(points)
+	has := func(i int, key string) bool {
+		_, ok := points[i].(map[string]any)[key]
+		return ok
+	}
+	for i := 0; i < n; i++ {
+		next := (i + 1) % n
+		if !closed && i == n-1 {
+			if has(i, "out") {
+				return &ValidationError{Field: fmt.Sprintf("%spoints[%d].out", prefix, i), Message: "the last point of an open curve has no segment leaving it"}
+			}
+			if has(0, "in") {
+				return &ValidationError{Field: prefix + "points[0].in", Message: "the first point of an open curve has no segment arriving at it"}
+			}
+			break
+		}
+		if has(i, "out") != has(next, "in") {
+			if has(i, "out") {
+				return &ValidationError{Field: fmt.Sprintf("%spoints[%d].out", prefix, i), Message: fmt.Sprintf("must be paired with %spoints[%d].in", prefix, next)}
+			}
+			return &ValidationError{Field: fmt.Sprintf("%spoints[%d].in", prefix, next), Message: fmt.Sprintf("must be paired with %spoints[%d].out", prefix, i)}
+		}
+	}
+	return nil
+}
+
+// coordinates checks that an object has numeric x and y fields.
+func coordinates(m map[string]any, field string) error {
+	for _, key := range []string{"x", "y"} {
+		if _, ok := m[key]; !ok {
+			return &ValidationError{Field: field + "." + key, Message: "missing"}
+		}
+		if _, err := number(m[key], field+"."+key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// object checks that v is a JSON object with only the given keys.
+func object(v any, field string, keys ...string) (map[string]any, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, &ValidationError{Field: field, Message: "must be an object"}
+	}
+	for _, key := range sortedKeys(m) {
+		known := false
+		for _, k := range keys {
+			known = known || k == key
+		}
+		if !known {
+			if field != "" {
+				key = field + "." + key
+			}
+			return nil, &ValidationError{Field: key, Message: "unknown field"}
+		}
+	}
+	return m, nil
+}
+
+// number checks that v is a finite JSON number and returns its value.
+func number(v any, field string) (float64, error) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, &ValidationError{Field: field, Message: "must be a number"}
+	}
+	f, err := n.Float64()
+	if err != nil || math.IsInf(f, 0) {
+		return 0, &ValidationError{Field: field, Message: "number out of range"}
+	}
+	return f, nil
+}
+
+// sortedKeys returns the keys of m in order, so the same document always
+// produces the same error.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,102 @@
+package scene
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateField(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		// field is the field the error names, or "-" if the scene is valid
+		field string
+	}{
+		{name: "valid", data: `{"version": 2, "paths": [{"points": [{"x": 0, "y": 0}, {"x": 1, "y": 1}]}]}`, field: "-"},
+		{name: "valid version 1", data: `{"version": 1, "points": [{"x": 0, "y": 0}, {"x": 1, "y": 1}]}`, field: "-"},
+		{name: "not JSON", data: `{"version": 2,`},
+		{name: "trailing data", data: `{"version": 2, "paths": [{"points": [{"x": 0, "y": 0}, {"x": 1, "y": 1}]}]} {}`},
+		{name: "not an object", data: `[]`},
+		{name: "no version", data: `{"paths": []}`, field: "version"},
+		{name: "fractional version", data: `{"version": 1.5}`, field: "version"},
+		{name: "future version", data: `{"version": 3}`, field: "version"},
+		{name: "unknown field", data: `{"version": 2, "paths": [], "zoom": 2}`, field: "zoom"},
+		{name: "no paths", data: `{"version": 2, "paths": []}`, field: "paths"},
+		{name: "paths not an array", data: `{"version": 2, "paths": {}}`, field: "paths"},
+		{name: "unknown path field", data: `{"version": 2, "paths": [{"points": [], "width": 2}]}`, field: "paths[0].width"},
+		{name: "bad algorithm", data: `{"version": 2, "paths": [{"algorithm": "bspline", "points": []}]}`, field: "paths[0].algorithm"},
+		{name: "omega too big", data: `{"version": 2, "paths": [{"omega": 2, "points": []}]}`, field: "paths[0].omega"},
+		{
+			name:  "closed natural",
+			data:  `{"version": 2, "paths": [{"algorithm": "natural", "closed": true, "points": []}]}`,
+			field: "paths[0].closed",
+		},
+		{name: "bad color", data: `{"version": 2, "paths": [{"color": "red", "points": []}]}`, field: "paths[0].color"},
+		{name: "too few points", data: `{"version": 2, "paths": [{"points": [{"x": 0, "y": 0}]}]}`, field: "paths[0].points"},
+		{
+			name:  "too few closed points",
+			data:  `{"version": 2, "paths": [{"closed": true, "points": [{"x": 0, "y": 0}, {"x": 1, "y": 1}]}]}`,
+			field: "paths[0].points",
+		},
+		{
+			name:  "missing coordinate in second path",
+			data:  `{"version": 2, "paths": [{"points": [{"x": 0, "y": 0}, {"x": 1, "y": 1}]}, {"points": [{"x": 0, "y": 0}, {"x": 1}]}]}`,
+			field: "paths[1].points[1].y",
+		},
+		{
+			name:  "handle not a point",
+			data:  `{"version": 2, "paths": [{"points": [{"x": 0, "y": 0, "out": {"x": 1}}, {"x": 1, "y": 1, "in": {"x": 0, "y": 1}}]}]}`,
+			field: "paths[0].points[0].out.y",
+		},
+		{
+			name:  "constraint without metapost",
+			data:  `{"version": 2, "paths": [{"points": [{"x": 0, "y": 0, "direction": 90}, {"x": 1, "y": 1}]}]}`,
+			field: "paths[0].points[0].direction",
+		},
+		{
+			name:  "curl and direction",
+			data:  `{"version": 2, "paths": [{"algorithm": "metapost", "points": [{"x": 0, "y": 0, "direction": 90, "curl": 1}, {"x": 1, "y": 1}]}]}`,
+			field: "paths[0].points[0].curl",
+		},
+		{
+			name:  "low tension",
+			data:  `{"version": 2, "paths": [{"algorithm": "metapost", "points": [{"x": 0, "y": 0}, {"x": 1, "y": 1, "tension": 0.5}]}]}`,
+			field: "paths[0].points[1].tension",
+		},
+		{
+			name:  "unpaired handle",
+			data:  `{"version": 2, "paths": [{"points": [{"x": 0, "y": 0, "out": {"x": 1, "y": 0}}, {"x": 1, "y": 1}]}]}`,
+			field: "paths[0].points[0].out",
+		},
+		{
+			name:  "handle arriving at the start",
+			data:  `{"version": 2, "paths": [{"points": [{"x": 0, "y": 0, "in": {"x": 1, "y": 0}}, {"x": 1, "y": 1}]}]}`,
+			field: "paths[0].points[0].in",
+		},
+		{
+			name:  "unpaired handle across the seam",
+			data:  `{"version": 2, "paths": [{"closed": true, "points": [{"x": 0, "y": 0, "in": {"x": 1, "y": 0}}, {"x": 1, "y": 1}, {"x": 2, "y": 0}]}]}`,
+			field: "paths[0].points[0].in",
+		},
+		{name: "view not a bool", data: `{"version": 1, "points": [{"x": 0, "y": 0}, {"x": 1, "y": 1}], "view": {"showComb": 1}}`, field: "view.showComb"},
+		{name: "version 1 field", data: `{"version": 1, "omega": -1, "points": [{"x": 0, "y": 0}, {"x": 1, "y": 1}]}`, field: "omega"},
+		{name: "paths in version 1", data: `{"version": 1, "paths": []}`, field: "paths"},
+	}
+	for _, tc := range tests {
+		err := Validate([]byte(tc.data))
+		if tc.field == "-" {
+			if err != nil {
+				t.Errorf("%s: %v", tc.name, err)
+			}
+			continue
+		}
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("%s: got %v, want a *ValidationError", tc.name, err)
+			continue
+		}
+		if verr.Field != tc.field {
+			t.Errorf("%s: error is about %q, want %q: %v", tc.name, verr.Field, tc.field, verr)
+		}
+	}
+}
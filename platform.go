@@ -0,0 +1,26 @@
+//go:build !js
+
+package main
+
+import "os"
+
+// saveFile writes data to a file of the given name in the working directory.
+func saveFile(name string, mimeType string, data []byte) error {
+	return os.WriteFile(name, data, 0644)
+}
+
+// queryParam returns the value of a URL query parameter of the page the demo
+// is running in. Outside the browser there is no page, so it's always empty.
+func queryParam(name string) string {
+	return ""
+}
+
+// writeScene saves a scene file of the given name in the working directory.
+func writeScene(name string, data []byte) error {
+	return os.WriteFile(name, data, 0644)
+}
+
+// readScene reads a scene file of the given name from the working directory.
+func readScene(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
@@ -0,0 +1,55 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"io/fs"
+	"syscall/js"
+)
+
+// saveFile offers data to the user as a download with the given file name,
+// since a page can't write to the filesystem directly.
+func saveFile(name string, mimeType string, data []byte) error {
+	array := js.Global().Get("Uint8Array").New(len(data))
+	js.CopyBytesToJS(array, data)
+
+	blob := js.Global().Get("Blob").New([]any{array}, map[string]any{"type": mimeType})
+	url := js.Global().Get("URL").Call("createObjectURL", blob)
+	defer js.Global().Get("URL").Call("revokeObjectURL", url)
+
+	document := js.Global().Get("document")
+	link := document.Call("createElement", "a")
+	link.Set("href", url)
+	link.Set("download", name)
+	document.Get("body").Call("appendChild", link)
+	link.Call("click")
+	document.Get("body").Call("removeChild", link)
+	return nil
+}
+
+// queryParam returns the value of a URL query parameter of the page the demo
+// is running in, or an empty string if it isn't set.
+func queryParam(name string) string {
+	search := js.Global().Get("location").Get("search")
+	value := js.Global().Get("URLSearchParams").New(search).Call("get", name)
+	if value.IsNull() {
+		return ""
+	}
+	return value.String()
+}
+
+// writeScene keeps a scene in the page's local storage under the given name,
+// where it survives reloads.
+func writeScene(name string, data []byte) error {
+	js.Global().Get("localStorage").Call("setItem", name, string(data))
+	return nil
+}
+
+// readScene returns a scene kept in the page's local storage by writeScene.
+func readScene(name string) ([]byte, error) {
+	value := js.Global().Get("localStorage").Call("getItem", name)
+	if value.IsNull() {
+		return nil, fs.ErrNotExist
+	}
+	return []byte(value.String()), nil
+}
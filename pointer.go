@@ -0,0 +1,182 @@
+package main
+
+import (
+	"math"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// pointerID identifies the mouse, or a finger on a touch screen.
+type pointerID int
+
+// mousePointer is the ID of the mouse, which can't clash with touch IDs since
+// they're never negative.
+const mousePointer pointerID = -1
+
+type pointerEventKind int
+
+const (
+	pointerPress pointerEventKind = iota
+	pointerMove
+	pointerRelease
+)
+
+// pointerEvent is something a pointer did during a tick. Everything that
+// edits the curve works from these events rather than polling the mouse and
+// touch screen directly, so it can be driven by synthetic ones.
+type pointerEvent struct {
+	kind  pointerEventKind
+	id    pointerID
+	touch bool
+	x, y  int
+}
+
+// pointer is the mouse while its left button is held, or a finger while it's
+// on the touch screen.
+type pointer struct {
+	id    pointerID
+	touch bool
+	x, y  int
+	// Where the pointer was the tick before, and where it was pressed
+	prevX, prevY   int
+	pressX, pressY int
+	// ticks counts the ticks since the pointer was pressed, and wandered is
+	// set once it has moved too far from where it was for a long press
+	ticks    int
+	wandered bool
+
+	justPressed  bool
+	justReleased bool
+}
+
+// longPressed reports whether a finger has just been held still for long
+// enough to count as a long press. It's only reported once per press.
+func (p *pointer) longPressed() bool {
+	return p.touch && !p.wandered && p.ticks == longPressTicks
+}
+
+// pointers tracks every pointer that's down, in the order they were pressed.
+type pointers struct {
+	down []*pointer
+	// released are the pointers released this tick
+	released []*pointer
+	// cursorX and cursorY are where the mouse or the last finger to move is,
+	// even when nothing is pressed
+	cursorX, cursorY int
+	// mouseX and mouseY are where poll last saw the mouse
+	mouseX, mouseY int
+}
+
+// update starts a new tick, applying the events that happened since the
+// last one.
+func (ps *pointers) update(events []pointerEvent) {
+	ps.released = ps.released[:0]
+	for _, p := range ps.down {
+		p.prevX, p.prevY = p.x, p.y
+		p.justPressed = false
+		p.ticks++
+	}
+
+	for _, e := range events {
+		ps.cursorX, ps.cursorY = e.x, e.y
+		i := slices.IndexFunc(ps.down, func(p *pointer) bool { return p.id == e.id })
+		switch {
+		case e.kind == pointerPress && i < 0:
+			ps.down = append(ps.down, &pointer{
+				id: e.id, touch: e.touch,
+				x: e.x, y: e.y, prevX: e.x, prevY: e.y, pressX: e.x, pressY: e.y,
+				justPressed: true,
+			})
+		case e.kind == pointerMove && i >= 0:
+			p := ps.down[i]
+			p.x, p.y = e.x, e.y
+			if math.Hypot(float64(p.x-p.pressX), float64(p.y-p.pressY)) > longPressDistance {
+				p.wandered = true
+			}
+		case e.kind == pointerRelease && i >= 0:
+			p := ps.down[i]
+			p.x, p.y = e.x, e.y
+			p.justReleased = true
+			ps.released = append(ps.released, p)
+			ps.down = slices.Delete(ps.down, i, i+1)
+		}
+	}
+}
+
+// primary returns the pointer the toolbar and single-pointer modes follow:
+// the first one still down, or failing that the last one released. It
+// returns nil if no pointer is down or was just released.
+func (ps *pointers) primary() *pointer {
+	if len(ps.down) != 0 {
+		return ps.down[0]
+	}
+	if len(ps.released) != 0 {
+		return ps.released[len(ps.released)-1]
+	}
+	return nil
+}
+
+// touches returns the fingers on the touch screen.
+func (ps *pointers) touches() []*pointer {
+	var touches []*pointer
+	for _, p := range ps.down {
+		if p.touch {
+			touches = append(touches, p)
+		}
+	}
+	return touches
+}
+
+// pinch returns how two pointers moved since the last tick, as a pinch
+// gesture: how far their midpoint moved, where it is now, and the ratio of
+// the distance between them to what it was.
+func pinch(a, b *pointer) (dx, dy, cx, cy, scale float64) {
+	cx, cy = float64(a.x+b.x)/2, float64(a.y+b.y)/2
+	dx = cx - float64(a.prevX+b.prevX)/2
+	dy = cy - float64(a.prevY+b.prevY)/2
+
+	scale = 1
+	prev := math.Hypot(float64(b.prevX-a.prevX), float64(b.prevY-a.prevY))
+	if d := math.Hypot(float64(b.x-a.x), float64(b.y-a.y)); prev > 0 && d > 0 {
+		scale = d / prev
+	}
+	return dx, dy, cx, cy, scale
+}
+
+// poll returns what the mouse, with its left button, and the touch screen
+// did since the last tick.
+func (ps *pointers) poll() []pointerEvent {
+	var events []pointerEvent
+
+	x, y := ebiten.CursorPosition()
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		events = append(events, pointerEvent{kind: pointerPress, id: mousePointer, x: x, y: y})
+	}
+	if x != ps.mouseX || y != ps.mouseY {
+		events = append(events, pointerEvent{kind: pointerMove, id: mousePointer, x: x, y: y})
+		ps.mouseX, ps.mouseY = x, y
+	}
+	if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) {
+		events = append(events, pointerEvent{kind: pointerRelease, id: mousePointer, x: x, y: y})
+	}
+
+	for _, id := range inpututil.AppendJustPressedTouchIDs(nil) {
+		x, y := ebiten.TouchPosition(id)
+		events = append(events, pointerEvent{kind: pointerPress, id: pointerID(id), touch: true, x: x, y: y})
+	}
+	for _, id := range ebiten.AppendTouchIDs(nil) {
+		x, y := ebiten.TouchPosition(id)
+		if px, py := inpututil.TouchPositionInPreviousTick(id); px != x || py != y {
+			events = append(events, pointerEvent{kind: pointerMove, id: pointerID(id), touch: true, x: x, y: y})
+		}
+	}
+	// Released fingers are no longer on the screen, so they're wherever they
+	// were last seen
+	for _, id := range inpututil.AppendJustReleasedTouchIDs(nil) {
+		x, y := inpututil.TouchPositionInPreviousTick(id)
+		events = append(events, pointerEvent{kind: pointerRelease, id: pointerID(id), touch: true, x: x, y: y})
+	}
+	return events
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func press(id pointerID, x, y int) pointerEvent {
+	return pointerEvent{kind: pointerPress, id: id, touch: id != mousePointer, x: x, y: y}
+}
+
+func move(id pointerID, x, y int) pointerEvent {
+	return pointerEvent{kind: pointerMove, id: id, touch: id != mousePointer, x: x, y: y}
+}
+
+func release(id pointerID, x, y int) pointerEvent {
+	return pointerEvent{kind: pointerRelease, id: id, touch: id != mousePointer, x: x, y: y}
+}
+
+func TestPointersTrackEachPointer(t *testing.T) {
+	var ps pointers
+	ps.update([]pointerEvent{press(0, 10, 10), press(mousePointer, 50, 50)})
+	if len(ps.down) != 2 || !ps.down[0].justPressed || !ps.down[1].justPressed {
+		t.Fatalf("pressing two pointers left %d down", len(ps.down))
+	}
+	if got := len(ps.touches()); got != 1 {
+		t.Fatalf("%d fingers on the screen, want 1", got)
+	}
+
+	ps.update([]pointerEvent{move(0, 20, 15), press(1, 100, 100)})
+	first := ps.down[0]
+	if first.justPressed || first.x != 20 || first.y != 15 || first.prevX != 10 || first.prevY != 10 {
+		t.Fatalf("moved finger is %+v", first)
+	}
+
+	ps.update([]pointerEvent{release(0, 25, 15)})
+	if len(ps.down) != 2 || len(ps.released) != 1 || ps.released[0].id != 0 || ps.released[0].x != 25 {
+		t.Fatalf("releasing a finger left %d down and %d released", len(ps.down), len(ps.released))
+	}
+	if p := ps.primary(); p.id != mousePointer {
+		t.Fatalf("primary pointer is %d, want the mouse", p.id)
+	}
+	ps.update(nil)
+	if len(ps.released) != 0 {
+		t.Fatalf("%d pointers still released a tick later", len(ps.released))
+	}
+}
+
+func TestPointersLongPress(t *testing.T) {
+	var ps pointers
+	ps.update([]pointerEvent{press(0, 100, 100), press(mousePointer, 200, 200)})
+	finger, mouse := ps.down[0], ps.down[1]
+
+	// Wobbling less than longPressDistance still counts as holding still
+	longPresses := 0
+	for tick := 1; tick <= 2*longPressTicks; tick++ {
+		ps.update([]pointerEvent{move(0, 100+tick%2*longPressDistance/2, 100)})
+		if finger.longPressed() {
+			longPresses++
+			if tick != longPressTicks {
+				t.Fatalf("long press after %d ticks, want %d", tick, longPressTicks)
+			}
+		}
+		if mouse.longPressed() {
+			t.Fatal("holding the mouse still was a long press")
+		}
+	}
+	if longPresses != 1 {
+		t.Fatalf("%d long presses, want 1", longPresses)
+	}
+
+	ps.update([]pointerEvent{press(1, 100, 100)})
+	wanderer := ps.down[len(ps.down)-1]
+	ps.update([]pointerEvent{move(1, 100+longPressDistance+1, 100)})
+	ps.update([]pointerEvent{move(1, 100, 100)})
+	for tick := 3; tick <= longPressTicks; tick++ {
+		ps.update(nil)
+		if wanderer.longPressed() {
+			t.Fatal("finger that moved away and back was a long press")
+		}
+	}
+}
+
+func TestPinch(t *testing.T) {
+	var ps pointers
+	ps.update([]pointerEvent{press(0, 100, 100), press(1, 200, 100)})
+	ps.update([]pointerEvent{move(0, 60, 110), move(1, 260, 110)})
+
+	dx, dy, cx, cy, scale := pinch(ps.down[0], ps.down[1])
+	if dx != 10 || dy != 10 {
+		t.Errorf("pinch moved by %g, %g, want 10, 10", dx, dy)
+	}
+	if cx != 160 || cy != 110 {
+		t.Errorf("pinch centred on %g, %g, want 160, 110", cx, cy)
+	}
+	if scale != 2 {
+		t.Errorf("pinch scaled by %g, want 2", scale)
+	}
+}
+
+func TestPinchZoomsCamera(t *testing.T) {
+	g := newTestGame()
+	start := g.points()
+
+	// Pinching away from the curve zooms around the midpoint of the
+	// fingers, without adding knots
+	g.tick(press(0, 100, 400))
+	g.tick(press(1, 200, 400))
+	anchor := g.camera.toWorld(150, 400)
+	g.tick(move(0, 50, 400), move(1, 250, 400))
+	if math.Abs(g.camera.zoom-2) > 1e-9 {
+		t.Fatalf("pinch zoomed to %g, want 2", g.camera.zoom)
+	}
+	if at := g.camera.toScreen(anchor); math.Abs(at.X-150) > 1e-9 || math.Abs(at.Y-400) > 1e-9 {
+		t.Fatalf("the midpoint of the pinch moved to %v", at)
+	}
+	g.tick(release(0, 50, 400), release(1, 250, 400))
+	g.tick()
+	checkPoints(t, g, start, "pinching")
+	if g.panning || len(g.history.done) != 0 {
+		t.Fatalf("pinch left panning %v with %d edits", g.panning, len(g.history.done))
+	}
+}
+
+func TestSimultaneousTouchDrags(t *testing.T) {
+	g := newTestGame()
+	start := g.points()
+	a, b := start[0], start[3]
+
+	g.tick(press(0, int(a.X), int(a.Y)))
+	g.tick(press(1, int(b.X), int(b.Y)))
+	if len(g.drags) != 2 || g.panning {
+		t.Fatalf("two fingers on knots made %d drags, panning %v", len(g.drags), g.panning)
+	}
+	for i := 1; i <= 5; i++ {
+		g.tick(move(0, int(a.X)+4*i, int(a.Y)), move(1, int(b.X), int(b.Y)-6*i))
+	}
+	g.tick(release(0, int(a.X)+20, int(a.Y)))
+	g.tick(release(1, int(b.X), int(b.Y)-30))
+
+	want := slices.Clone(start)
+	want[0].X += 20
+	want[3].Y -= 30
+	checkPoints(t, g, want, "dragging two knots at once")
+	if len(g.history.done) != 1 {
+		t.Fatalf("dragging two knots at once made %d edits, want 1", len(g.history.done))
+	}
+	g.undo()
+	checkPoints(t, g, start, "undoing the drags")
+}
+
+func TestLongPressRemovesKnot(t *testing.T) {
+	g := newTestGame()
+	start := g.points()
+	k := start[2]
+
+	g.tick(press(0, int(k.X), int(k.Y)))
+	for tick := 1; tick < longPressTicks; tick++ {
+		g.tick()
+	}
+	checkPoints(t, g, start, "holding a finger on a knot")
+	g.tick()
+	if got := len(g.path().Points); got != len(start)-1 {
+		t.Fatalf("long press left %d knots, want %d", got, len(start)-1)
+	}
+	g.tick(release(0, int(k.X), int(k.Y)))
+	if len(g.history.done) != 1 {
+		t.Fatalf("long press made %d edits, want 1", len(g.history.done))
+	}
+	g.undo()
+	checkPoints(t, g, start, "undoing the long press")
+}
+
+// A knot added by a finger is selected, but cancelling the drag that added
+// it must not leave it selected once it's gone.
+func TestCancelledDragRestoresSelection(t *testing.T) {
+	tests := []struct {
+		name string
+		// cancel cancels the drag of the finger with ID 0 somehow
+		cancel func(g *Game)
+	}{
+		{
+			name: "pinch",
+			cancel: func(g *Game) {
+				g.tick(press(1, 700, 500))
+				g.tick(release(1, 700, 500))
+			},
+		},
+		{
+			name: "long press",
+			cancel: func(g *Game) {
+				for tick := 0; tick < longPressTicks; tick++ {
+					g.tick()
+				}
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := newTestGame()
+			start := g.points()
+			g.selection = []int{1, 2}
+
+			g.tick(press(0, 600, 450))
+			if g.selectedKnot() != len(start) {
+				t.Fatalf("adding a knot selected %v, want %d", g.selection, len(start))
+			}
+			tc.cancel(g)
+			g.tick(release(0, 600, 450))
+			g.tick()
+
+			checkPoints(t, g, start, "cancelling adding a knot")
+			if len(g.selection) != 2 || g.selection[0] != 1 || g.selection[1] != 2 {
+				t.Fatalf("cancelling the drag left %v selected, want [1 2]", g.selection)
+			}
+			g.nudge(10, 0, false)
+			g.resetHandles(g.selection)
+			if _, _, _, ok := g.selectionBounds(g.path().Points); !ok {
+				t.Fatal("no gizmo around the restored selection")
+			}
+		})
+	}
+}
+
+func TestStaleSelectionIgnored(t *testing.T) {
+	g := newTestGame()
+	n := len(g.path().Points)
+	g.selection = []int{0, n, n + 3}
+
+	g.nudge(10, 0, false)
+	g.resetHandles(g.selection)
+	g.removeKnots(g.selection)
+	if got := len(g.path().Points); got != n-1 {
+		t.Fatalf("removing the selection left %d knots, want %d", got, n-1)
+	}
+	g.selection = []int{n + 1}
+	if _, _, _, ok := g.selectionBounds(g.path().Points); ok {
+		t.Fatal("gizmo around knots that don't exist")
+	}
+	if i := g.selectedKnot(); i != -1 {
+		t.Fatalf("selected knot is %d, want none", i)
+	}
+}
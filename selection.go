@@ -0,0 +1,429 @@
+package main
+
+import (
+	"image"
+	"math"
+	"slices"
+
+	"github.com/braheezy/hobby-spline/pkg/bezier"
+	"github.com/braheezy/hobby-spline/pkg/scene"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// toolKind says what a drag that isn't of a single knot does.
+type toolKind int
+
+const (
+	// boxTool and lassoTool add the knots inside a box or lasso to the
+	// selection
+	boxTool toolKind = iota
+	lassoTool
+	// moveTool, rotateTool and scaleTool transform the selected knots
+	moveTool
+	rotateTool
+	scaleTool
+)
+
+// toolDrag is a drag that selects knots, or transforms the selected ones
+// with the gizmo around them.
+type toolDrag struct {
+	kind toolKind
+	// from are the knots as they were when the drag started, which the
+	// transform is applied to afresh on every move
+	from []scene.Knot
+	// pivot is the point the selection rotates or scales around, and corner
+	// the point of its bounds the scale handle dragged started on, both in
+	// world coordinates
+	pivot  bezier.Point
+	corner bezier.Point
+	// sideX and sideY say which side of the bounds a scale handle is on
+	// along each axis: -1, 1, or 0 for the middle, which isn't scaled along
+	sideX, sideY int
+	// selectionStart is the selection the knots in the box or lasso are
+	// added to, and marquee the box's corners or the lasso's points on screen
+	selectionStart []int
+	marquee        []image.Point
+}
+
+// gizmoHandle is one of the handles on the gizmo around the selection.
+type gizmoHandle struct {
+	// at is the position of the handle on screen
+	at           bezier.Point
+	kind         toolKind
+	sideX, sideY int
+}
+
+// selected reports whether the knot at index i is selected.
+func (g *Game) selected(i int) bool {
+	return slices.Contains(g.selection, i)
+}
+
+// selectedKnot returns the index of the selected knot, or -1 if there isn't
+// exactly one.
+func (g *Game) selectedKnot() int {
+	selection := g.selectedKnots()
+	if len(selection) != 1 {
+		return -1
+	}
+	return selection[0]
+}
+
+// selectedKnots returns the selection, leaving out any knots the current
+// path doesn't have, e.g. because they were removed by undoing or
+// cancelling the edit that selected them.
+func (g *Game) selectedKnots() []int {
+	return existingKnots(g.selection, len(g.path().Points))
+}
+
+// existingKnots returns the indices that are of knots of a path with n
+// knots.
+func existingKnots(indices []int, n int) []int {
+	return slices.DeleteFunc(slices.Clone(indices), func(i int) bool { return i < 0 || i >= n })
+}
+
+// toggleSelected adds the knot at index i to the selection, or removes it if
+// it's already there.
+func (g *Game) toggleSelected(i int) {
+	if j := slices.Index(g.selection, i); j >= 0 {
+		g.selection = slices.Delete(slices.Clone(g.selection), j, j+1)
+		return
+	}
+	g.selection = append(slices.Clone(g.selection), i)
+	slices.Sort(g.selection)
+}
+
+// selectionBounds returns the bounding box of the selected knots in world
+// coordinates, and their centroid. ok is false unless at least two knots are
+// selected, since there's nothing to transform as a group otherwise.
+func (g *Game) selectionBounds(knots []scene.Knot) (minP, maxP, centroid bezier.Point, ok bool) {
+	selection := existingKnots(g.selection, len(knots))
+	if len(selection) < 2 {
+		return minP, maxP, centroid, false
+	}
+	minP = bezier.Point{X: math.Inf(1), Y: math.Inf(1)}
+	maxP = bezier.Point{X: math.Inf(-1), Y: math.Inf(-1)}
+	for _, i := range selection {
+		k := knots[i]
+		minP.X, maxP.X = math.Min(minP.X, k.X), math.Max(maxP.X, k.X)
+		minP.Y, maxP.Y = math.Min(minP.Y, k.Y), math.Max(maxP.Y, k.Y)
+		centroid.X += k.X / float64(len(selection))
+		centroid.Y += k.Y / float64(len(selection))
+	}
+	return minP, maxP, centroid, true
+}
+
+// gizmoRect returns the box of the gizmo around the selection on screen.
+func (g *Game) gizmoRect() (bezier.Point, bezier.Point, bool) {
+	minP, maxP, _, ok := g.selectionBounds(g.path().Points)
+	if !ok {
+		return minP, maxP, false
+	}
+	minP, maxP = g.camera.toScreen(minP), g.camera.toScreen(maxP)
+	minP.X, minP.Y = minP.X-gizmoMargin, minP.Y-gizmoMargin
+	maxP.X, maxP.Y = maxP.X+gizmoMargin, maxP.Y+gizmoMargin
+	return minP, maxP, true
+}
+
+// gizmoHandles returns the handles of the gizmo around the selection: one to
+// scale it at each corner and in the middle of each side, and one to rotate
+// it above the top.
+func (g *Game) gizmoHandles() []gizmoHandle {
+	minP, maxP, ok := g.gizmoRect()
+	if !ok {
+		return nil
+	}
+	var handles []gizmoHandle
+	for sideY := -1; sideY <= 1; sideY++ {
+		for sideX := -1; sideX <= 1; sideX++ {
+			if sideX != 0 || sideY != 0 {
+				handles = append(handles, gizmoHandle{at: boundsSide(minP, maxP, sideX, sideY), kind: scaleTool, sideX: sideX, sideY: sideY})
+			}
+		}
+	}
+	rotate := boundsSide(minP, maxP, 0, -1)
+	rotate.Y -= rotateHandleDistance
+	return append(handles, gizmoHandle{at: rotate, kind: rotateTool})
+}
+
+// startTool starts selecting knots, or transforming the selected ones, if
+// that's what p was pressed to do, and returns nil otherwise.
+//
+// Pressing with Shift held starts a box, and with Ctrl held a lasso, adding
+// the knots inside to the selection; pressing on a knot this way also
+// toggles whether it's selected. While several knots are selected, pressing
+// on one of them or inside the gizmo around them moves them all, and
+// pressing on a handle of the gizmo scales or rotates them.
+func (g *Game) startTool(p *pointer) *toolDrag {
+	path := g.path()
+	t := &toolDrag{from: slices.Clone(path.Points)}
+	at := bezier.Point{X: float64(p.x), Y: float64(p.y)}
+	minP, maxP, centroid, grouped := g.selectionBounds(path.Points)
+
+	for _, h := range g.gizmoHandles() {
+		if distance(h.at, at) > handleGrabRadius {
+			continue
+		}
+		t.kind, t.sideX, t.sideY = h.kind, h.sideX, h.sideY
+		t.pivot = centroid
+		if h.kind == scaleTool {
+			t.pivot, t.corner = boundsSide(minP, maxP, -h.sideX, -h.sideY), boundsSide(minP, maxP, h.sideX, h.sideY)
+		}
+		return t
+	}
+
+	knot := path.knotAt(g.camera, p.x, p.y)
+	shift := ebiten.IsKeyPressed(ebiten.KeyShift)
+	ctrl := ebiten.IsKeyPressed(ebiten.KeyControl) || ebiten.IsKeyPressed(ebiten.KeyMeta)
+	if shift || ctrl {
+		t.kind = boxTool
+		if ctrl {
+			t.kind = lassoTool
+		}
+		if knot >= 0 {
+			g.toggleSelected(knot)
+		}
+		t.selectionStart = g.selection
+		t.marquee = []image.Point{image.Pt(p.x, p.y)}
+		return t
+	}
+
+	if !grouped {
+		return nil
+	}
+	if knot >= 0 && g.selected(knot) {
+		t.kind = moveTool
+		return t
+	}
+	if gizmoMin, gizmoMax, _ := g.gizmoRect(); knot < 0 && at.X >= gizmoMin.X && at.X <= gizmoMax.X && at.Y >= gizmoMin.Y && at.Y <= gizmoMax.Y {
+		t.kind = moveTool
+		return t
+	}
+	return nil
+}
+
+// moveTool carries on with the selection or transform t as p moves.
+func (g *Game) moveTool(t *toolDrag, p *pointer) {
+	if t.kind == boxTool || t.kind == lassoTool {
+		g.selectWithin(t, p)
+		return
+	}
+
+	press := g.camera.toWorld(float64(p.pressX), float64(p.pressY))
+	cursor := g.camera.toWorld(float64(p.x), float64(p.y))
+	var m affine
+	switch t.kind {
+	case moveTool:
+		m = translation(cursor.X-press.X, cursor.Y-press.Y)
+	case rotateTool:
+		angle := math.Atan2(cursor.Y-t.pivot.Y, cursor.X-t.pivot.X) - math.Atan2(press.Y-t.pivot.Y, press.X-t.pivot.X)
+		if g.snapping() {
+			angle = math.Round(angle/snapAngle) * snapAngle
+		}
+		m = rotation(t.pivot, angle)
+	case scaleTool:
+		// The corner follows the pointer, and the opposite one stays put.
+		// Corners scale uniformly unless Shift is held.
+		to := bezier.Point{X: t.corner.X + cursor.X - press.X, Y: t.corner.Y + cursor.Y - press.Y}
+		from := bezier.Point{X: t.corner.X - t.pivot.X, Y: t.corner.Y - t.pivot.Y}
+		sx, sy := 1.0, 1.0
+		if t.sideX != 0 && from.X != 0 {
+			sx = (to.X - t.pivot.X) / from.X
+		}
+		if t.sideY != 0 && from.Y != 0 {
+			sy = (to.Y - t.pivot.Y) / from.Y
+		}
+		if t.sideX != 0 && t.sideY != 0 && !ebiten.IsKeyPressed(ebiten.KeyShift) {
+			if l := from.X*from.X + from.Y*from.Y; l > 0 {
+				s := ((to.X-t.pivot.X)*from.X + (to.Y-t.pivot.Y)*from.Y) / l
+				sx, sy = s, s
+			}
+		}
+		m = scaling(t.pivot, sx, sy)
+	}
+
+	path := g.path()
+	for _, i := range g.selectedKnots() {
+		path.Points[i] = m.knot(t.from[i])
+	}
+}
+
+// selectWithin selects the knots inside the box or lasso t, once p has
+// moved far enough from where it was pressed to be drawing one.
+func (g *Game) selectWithin(t *toolDrag, p *pointer) {
+	if t.kind == lassoTool {
+		t.marquee = append(t.marquee, image.Pt(p.x, p.y))
+	} else {
+		t.marquee = []image.Point{t.marquee[0], image.Pt(p.x, p.y)}
+	}
+	if !p.wandered {
+		return
+	}
+
+	selection := slices.Clone(t.selectionStart)
+	box := image.Rectangle{Min: t.marquee[0], Max: t.marquee[len(t.marquee)-1]}.Canon()
+	for i, k := range g.path().Points {
+		s := g.camera.toScreen(bezier.Point{X: k.X, Y: k.Y})
+		inside := s.X >= float64(box.Min.X) && s.X <= float64(box.Max.X) && s.Y >= float64(box.Min.Y) && s.Y <= float64(box.Max.Y)
+		if t.kind == lassoTool {
+			inside = inPolygon(s, t.marquee)
+		}
+		if inside && !slices.Contains(selection, i) {
+			selection = append(selection, i)
+		}
+	}
+	slices.Sort(selection)
+	g.selection = selection
+}
+
+// transformSelection transforms the selected knots by m as a single edit.
+func (g *Game) transformSelection(m affine) {
+	path := g.path()
+	selection := g.selectedKnots()
+	points := slices.Clone(path.Points)
+	for _, i := range selection {
+		points[i] = m.knot(points[i])
+	}
+	g.apply(&setPoints{path: path, from: path.Points, to: points, selection: selection})
+}
+
+// updateSelectionButtons declares the buttons under the gizmo that mirror
+// the selection, unless it's being dragged.
+func (g *Game) updateSelectionButtons() {
+	minP, maxP, ok := g.gizmoRect()
+	if !ok || g.dragging() {
+		return
+	}
+	y := min(int(maxP.Y)+widgetGap/2, screenHeight-toolbarHeight-widgetHeight)
+	r := row{x: int(minP.X), y: y, height: widgetHeight}
+	_, _, centroid, _ := g.selectionBounds(g.path().Points)
+	if g.ui.button("flipX", r.next(textWidth("Flip X", textFont)+widgetGap), "Flip X") {
+		g.transformSelection(scaling(centroid, -1, 1))
+	}
+	if g.ui.button("flipY", r.next(textWidth("Flip Y", textFont)+widgetGap), "Flip Y") {
+		g.transformSelection(scaling(centroid, 1, -1))
+	}
+}
+
+// drawSelection draws the gizmo around the selection, or the box or lasso
+// selecting knots.
+func (g *Game) drawSelection(screen *ebiten.Image) {
+	for _, d := range g.drags {
+		if d.tool == nil || (d.tool.kind != boxTool && d.tool.kind != lassoTool) {
+			continue
+		}
+		m := d.tool.marquee
+		if d.tool.kind == boxTool {
+			box := image.Rectangle{Min: m[0], Max: m[len(m)-1]}.Canon()
+			vector.StrokeRect(screen, float32(box.Min.X), float32(box.Min.Y), float32(box.Dx()), float32(box.Dy()), 1, gizmoColor, true)
+			return
+		}
+		for i := range m {
+			a, b := m[i], m[(i+1)%len(m)]
+			vector.StrokeLine(screen, float32(a.X), float32(a.Y), float32(b.X), float32(b.Y), 1, gizmoColor, true)
+		}
+		return
+	}
+
+	minP, maxP, ok := g.gizmoRect()
+	if !ok {
+		return
+	}
+	vector.StrokeRect(screen, float32(minP.X), float32(minP.Y), float32(maxP.X-minP.X), float32(maxP.Y-minP.Y), 1, gizmoColor, true)
+	for _, h := range g.gizmoHandles() {
+		x, y := float32(h.at.X), float32(h.at.Y)
+		if h.kind == rotateTool {
+			vector.StrokeLine(screen, x, y, x, float32(minP.Y), 1, gizmoColor, true)
+			vector.DrawFilledCircle(screen, x, y, handleDiameter/2+1, gizmoColor, true)
+			continue
+		}
+		vector.DrawFilledRect(screen, x-handleDiameter/2, y-handleDiameter/2, handleDiameter, handleDiameter, gizmoColor, true)
+	}
+}
+
+// boundsSide returns the point of the box from minP to maxP on the given
+// sides: -1 for the min side, 1 for the max side and 0 for the middle.
+func boundsSide(minP, maxP bezier.Point, sideX, sideY int) bezier.Point {
+	p := bezier.Point{X: (minP.X + maxP.X) / 2, Y: (minP.Y + maxP.Y) / 2}
+	switch sideX {
+	case -1:
+		p.X = minP.X
+	case 1:
+		p.X = maxP.X
+	}
+	switch sideY {
+	case -1:
+		p.Y = minP.Y
+	case 1:
+		p.Y = maxP.Y
+	}
+	return p
+}
+
+// inPolygon reports whether p is inside the polygon with the given vertices,
+// by counting the edges a ray from p crosses.
+func inPolygon(p bezier.Point, polygon []image.Point) bool {
+	inside := false
+	for i := range polygon {
+		a, b := polygon[i], polygon[(i+1)%len(polygon)]
+		ax, ay, bx, by := float64(a.X), float64(a.Y), float64(b.X), float64(b.Y)
+		if (ay > p.Y) != (by > p.Y) && p.X < ax+(p.Y-ay)*(bx-ax)/(by-ay) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// affine is the transform x' = a*x + b*y + e, y' = c*x + d*y + f.
+type affine struct {
+	a, b, c, d, e, f float64
+}
+
+// translation returns the transform moving points by dx, dy.
+func translation(dx, dy float64) affine {
+	return affine{a: 1, d: 1, e: dx, f: dy}
+}
+
+// rotation returns the transform rotating points by angle radians around
+// pivot.
+func rotation(pivot bezier.Point, angle float64) affine {
+	cos, sin := math.Cos(angle), math.Sin(angle)
+	return affine{
+		a: cos, b: -sin, c: sin, d: cos,
+		e: pivot.X - cos*pivot.X + sin*pivot.Y,
+		f: pivot.Y - sin*pivot.X - cos*pivot.Y,
+	}
+}
+
+// scaling returns the transform scaling points by sx and sy away from
+// pivot. A negative scale mirrors them.
+func scaling(pivot bezier.Point, sx, sy float64) affine {
+	return affine{a: sx, d: sy, e: pivot.X * (1 - sx), f: pivot.Y * (1 - sy)}
+}
+
+// apply returns x, y transformed.
+func (m affine) apply(x, y float64) (float64, float64) {
+	return m.a*x + m.b*y + m.e, m.c*x + m.d*y + m.f
+}
+
+// knot returns k transformed, along with its handles and the direction it
+// fixes, if any.
+func (m affine) knot(k scene.Knot) scene.Knot {
+	k.X, k.Y = m.apply(k.X, k.Y)
+	if k.In != nil {
+		x, y := m.apply(k.In.X, k.In.Y)
+		k.In = &scene.Handle{X: x, Y: y}
+	}
+	if k.Out != nil {
+		x, y := m.apply(k.Out.X, k.Out.Y)
+		k.Out = &scene.Handle{X: x, Y: y}
+	}
+	if k.Direction != nil {
+		// Directions aren't moved, only turned
+		rad := *k.Direction * math.Pi / 180
+		dx, dy := math.Cos(rad), math.Sin(rad)
+		direction := math.Atan2(m.c*dx+m.d*dy, m.a*dx+m.b*dy) * 180 / math.Pi
+		k.Direction = &direction
+	}
+	return k
+}
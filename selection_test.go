@@ -0,0 +1,225 @@
+package main
+
+import (
+	"image"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/braheezy/hobby-spline/pkg/bezier"
+	"github.com/braheezy/hobby-spline/pkg/scene"
+)
+
+func TestInPolygon(t *testing.T) {
+	box := []image.Point{{10, 10}, {110, 10}, {110, 60}, {10, 60}}
+	// A lasso shaped like a U, open at the top
+	lasso := []image.Point{{0, 0}, {30, 0}, {30, 70}, {70, 70}, {70, 0}, {100, 0}, {100, 100}, {0, 100}}
+	tests := []struct {
+		name    string
+		polygon []image.Point
+		p       bezier.Point
+		want    bool
+	}{
+		{name: "inside box", polygon: box, p: bezier.Point{X: 50, Y: 30}, want: true},
+		{name: "left of box", polygon: box, p: bezier.Point{X: 5, Y: 30}},
+		{name: "right of box", polygon: box, p: bezier.Point{X: 115, Y: 30}},
+		{name: "above box", polygon: box, p: bezier.Point{X: 50, Y: 5}},
+		{name: "below box", polygon: box, p: bezier.Point{X: 50, Y: 65}},
+		{name: "left arm of lasso", polygon: lasso, p: bezier.Point{X: 15, Y: 30}, want: true},
+		{name: "right arm of lasso", polygon: lasso, p: bezier.Point{X: 85, Y: 30}, want: true},
+		{name: "bottom of lasso", polygon: lasso, p: bezier.Point{X: 50, Y: 85}, want: true},
+		// Between the arms, a ray to the right crosses the lasso twice
+		{name: "inside the U", polygon: lasso, p: bezier.Point{X: 50, Y: 30}},
+		{name: "beyond lasso", polygon: lasso, p: bezier.Point{X: 150, Y: 30}},
+		{name: "too short", polygon: lasso[:2], p: bezier.Point{X: 15, Y: 0}},
+	}
+	for _, tc := range tests {
+		if got := inPolygon(tc.p, tc.polygon); got != tc.want {
+			t.Errorf("%s: inPolygon(%v) = %v, want %v", tc.name, tc.p, got, tc.want)
+		}
+	}
+}
+
+func TestAffineKnot(t *testing.T) {
+	direction := 30.0
+	k := scene.Knot{X: 10, Y: 20, Direction: &direction, In: &scene.Handle{X: 0, Y: 20}, Out: &scene.Handle{X: 20, Y: 30}}
+	pivot := bezier.Point{X: 100, Y: 100}
+	tests := []struct {
+		name string
+		m    affine
+		want scene.Knot
+		// direction is where the knot's direction turns to
+		direction float64
+	}{
+		{
+			name:      "translation",
+			m:         translation(5, -5),
+			want:      scene.Knot{X: 15, Y: 15, In: &scene.Handle{X: 5, Y: 15}, Out: &scene.Handle{X: 25, Y: 25}},
+			direction: 30,
+		},
+		{
+			name:      "rotation",
+			m:         rotation(pivot, math.Pi/2),
+			want:      scene.Knot{X: 180, Y: 10, In: &scene.Handle{X: 180, Y: 0}, Out: &scene.Handle{X: 170, Y: 20}},
+			direction: 120,
+		},
+		{
+			name:      "scaling",
+			m:         scaling(pivot, 2, 2),
+			want:      scene.Knot{X: -80, Y: -60, In: &scene.Handle{X: -100, Y: -60}, Out: &scene.Handle{X: -60, Y: -40}},
+			direction: 30,
+		},
+		{
+			name:      "mirroring in x",
+			m:         scaling(pivot, -1, 1),
+			want:      scene.Knot{X: 190, Y: 20, In: &scene.Handle{X: 200, Y: 20}, Out: &scene.Handle{X: 180, Y: 30}},
+			direction: 150,
+		},
+		{
+			name:      "mirroring in y",
+			m:         scaling(pivot, 1, -1),
+			want:      scene.Knot{X: 10, Y: 180, In: &scene.Handle{X: 0, Y: 180}, Out: &scene.Handle{X: 20, Y: 170}},
+			direction: -30,
+		},
+	}
+	for _, tc := range tests {
+		got := tc.m.knot(k)
+		if got.Direction == nil {
+			t.Fatalf("%s: lost the direction", tc.name)
+		}
+		if math.Abs(*got.Direction-tc.direction) > 1e-9 {
+			t.Errorf("%s: direction turned to %g, want %g", tc.name, *got.Direction, tc.direction)
+		}
+		got.Direction = nil
+		if !knotNear(got, tc.want) {
+			t.Errorf("%s: knot moved to %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+	if direction != 30 || k.X != 10 || k.In.X != 0 || k.Out.X != 20 {
+		t.Error("transforming a knot changed the original")
+	}
+}
+
+// knotNear reports whether knots a and b, and their handles, are in the same
+// places to within rounding.
+func knotNear(a, b scene.Knot) bool {
+	near := func(x, y float64) bool { return math.Abs(x-y) < 1e-9 }
+	handleNear := func(a, b *scene.Handle) bool {
+		if a == nil || b == nil {
+			return a == b
+		}
+		return near(a.X, b.X) && near(a.Y, b.Y)
+	}
+	return near(a.X, b.X) && near(a.Y, b.Y) && handleNear(a.In, b.In) && handleNear(a.Out, b.Out)
+}
+
+func TestGizmoScalePivots(t *testing.T) {
+	start := newTestGame().points()
+	var selected []scene.Knot
+	for _, i := range []int{0, 1, 2} {
+		selected = append(selected, start[i])
+	}
+	minP := bezier.Point{X: math.Inf(1), Y: math.Inf(1)}
+	maxP := bezier.Point{X: math.Inf(-1), Y: math.Inf(-1)}
+	for _, k := range selected {
+		minP.X, maxP.X = math.Min(minP.X, k.X), math.Max(maxP.X, k.X)
+		minP.Y, maxP.Y = math.Min(minP.Y, k.Y), math.Max(maxP.Y, k.Y)
+	}
+
+	for sideY := -1; sideY <= 1; sideY++ {
+		for sideX := -1; sideX <= 1; sideX++ {
+			if sideX == 0 && sideY == 0 {
+				continue
+			}
+			g := newTestGame()
+			g.selection = []int{0, 1, 2}
+			var handle gizmoHandle
+			found := false
+			for _, h := range g.gizmoHandles() {
+				if h.kind == scaleTool && h.sideX == sideX && h.sideY == sideY {
+					handle, found = h, true
+				}
+			}
+			if !found {
+				t.Fatalf("no handle scaling from side %d, %d", sideX, sideY)
+			}
+
+			// Dragging a handle outwards by a quarter of the box scales
+			// away from the opposite side, which stays put
+			x, y := int(math.Round(handle.at.X)), int(math.Round(handle.at.Y))
+			dx, dy := sideX*int(maxP.X-minP.X)/4, sideY*int(maxP.Y-minP.Y)/4
+			g.tick(press(mousePointer, x, y))
+			g.tick(move(mousePointer, x+dx/2, y+dy/2))
+			g.tick(move(mousePointer, x+dx, y+dy))
+			g.tick(release(mousePointer, x+dx, y+dy))
+
+			pivot := boundsSide(minP, maxP, -sideX, -sideY)
+			sx, sy := 1.0, 1.0
+			if sideX != 0 {
+				sx = 1 + float64(dx)/(boundsSide(minP, maxP, sideX, 0).X-pivot.X)
+			}
+			if sideY != 0 {
+				sy = 1 + float64(dy)/(boundsSide(minP, maxP, 0, sideY).Y-pivot.Y)
+			}
+			// Corners scale uniformly, by how far along the diagonal the
+			// pointer moved
+			if sideX != 0 && sideY != 0 {
+				from := bezier.Point{X: float64(sideX) * (maxP.X - minP.X), Y: float64(sideY) * (maxP.Y - minP.Y)}
+				s := 1 + (float64(dx)*from.X+float64(dy)*from.Y)/(from.X*from.X+from.Y*from.Y)
+				sx, sy = s, s
+			}
+
+			want := slices.Clone(start)
+			for i := range selected {
+				want[i] = scaling(pivot, sx, sy).knot(start[i])
+			}
+			got := g.points()
+			if !slices.EqualFunc(got, want, knotNear) {
+				t.Errorf("scaling from side %d, %d about %v: knots are %v, want %v", sideX, sideY, pivot, got, want)
+			}
+			if len(g.history.done) != 1 {
+				t.Errorf("scaling from side %d, %d made %d edits, want 1", sideX, sideY, len(g.history.done))
+			}
+		}
+	}
+}
+
+func TestTransformStaleSelection(t *testing.T) {
+	g := newTestGame()
+	start := g.points()
+	// Knot 10 was selected, then removed, e.g. by undoing adding it
+	g.selection = []int{0, 1, 10}
+	g.transformSelection(translation(5, 0))
+
+	want := slices.Clone(start)
+	want[0].X += 5
+	want[1].X += 5
+	checkPoints(t, g, want, "moving a selection with a knot that's gone")
+	if !slices.Equal(g.selection, []int{0, 1}) {
+		t.Fatalf("selection is %v, want the knots left of it", g.selection)
+	}
+	g.undo()
+	checkPoints(t, g, start, "undoing the move")
+}
+
+func TestMoveStaleSelection(t *testing.T) {
+	g := newTestGame()
+	start := g.points()
+	g.selection = []int{0, 1, 10}
+
+	// Pressing on a selected knot moves the whole selection
+	k := start[0]
+	x, y := int(k.X), int(k.Y)
+	g.tick(press(mousePointer, x, y))
+	g.tick(move(mousePointer, x+5, y+10))
+	g.tick(release(mousePointer, x+10, y+20))
+
+	want := slices.Clone(start)
+	for _, i := range []int{0, 1} {
+		want[i].X += 10
+		want[i].Y += 20
+	}
+	checkPoints(t, g, want, "dragging a selection with a knot that's gone")
+	g.undo()
+	checkPoints(t, g, start, "undoing the drag")
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"math"
+
+	"github.com/braheezy/hobby-spline/pkg/bezier"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// guide is a line showing what a dragged knot snapped to. A guide whose ends
+// are the same marks a point instead.
+type guide struct {
+	from, to bezier.Point
+}
+
+// altPressed reports whether Alt is held. It's a variable so that tests can
+// hold it down without a window.
+var altPressed = func() bool { return ebiten.IsKeyPressed(ebiten.KeyAlt) }
+
+// snapping reports whether dragged knots should snap, which holding Alt
+// turns off for as long as it's held.
+func (g *Game) snapping() bool {
+	return g.snap && !altPressed()
+}
+
+// snapKnot returns where the knot at index i, dragged by d to p, should go,
+// and adds guides showing why to g.guides.
+//
+// In order of preference, the knot snaps to the curve as it was before the
+// drag, to the x and y of the other knots, so that the chord from the knot
+// before it is a multiple of snapAngle, and to the grid. All but the grid
+// only snap within snapDistance pixels on screen.
+func (g *Game) snapKnot(i int, d *drag, p bezier.Point) bezier.Point {
+	within := snapDistance / g.camera.zoom
+
+	// The curve beats everything else, since it's what a knot is most
+	// likely to be dropped onto on purpose
+	best, bestDistance, found := p, within, false
+	for _, curve := range d.snapTo {
+		onCurve, _ := curve.Project(p)
+		if dist := distance(onCurve, p); dist <= bestDistance {
+			best, bestDistance, found = onCurve, dist, true
+		}
+	}
+	if found {
+		g.guides = append(g.guides, guide{best, best})
+		return best
+	}
+
+	// Line the knot up with the others, one axis at a time
+	knots := g.path().Points
+	snapped := p
+	alignedX, alignedY := false, false
+	bestX, bestY := within, within
+	for j, k := range knots {
+		if j == i || g.dragged(j) {
+			continue
+		}
+		if dx := math.Abs(k.X - p.X); dx <= bestX {
+			snapped.X, bestX, alignedX = k.X, dx, true
+		}
+		if dy := math.Abs(k.Y - p.Y); dy <= bestY {
+			snapped.Y, bestY, alignedY = k.Y, dy, true
+		}
+	}
+	for j, k := range knots {
+		if j == i {
+			continue
+		}
+		if alignedX && k.X == snapped.X {
+			g.guides = append(g.guides, guide{bezier.Point{X: k.X, Y: k.Y}, snapped})
+		}
+		if alignedY && k.Y == snapped.Y {
+			g.guides = append(g.guides, guide{bezier.Point{X: k.X, Y: k.Y}, snapped})
+		}
+	}
+	if alignedX && alignedY {
+		return snapped
+	}
+
+	// Keep the chord from the previous knot at a round angle, as long as
+	// that doesn't undo the alignment
+	if n := g.previousKnot(i); n >= 0 && !alignedX && !alignedY {
+		from := bezier.Point{X: knots[n].X, Y: knots[n].Y}
+		angle := math.Atan2(p.Y-from.Y, p.X-from.X)
+		rounded := math.Round(angle/snapAngle) * snapAngle
+		length := distance(from, p)
+		onChord := bezier.Point{X: from.X + length*math.Cos(rounded), Y: from.Y + length*math.Sin(rounded)}
+		if length > 0 && distance(onChord, p) <= within {
+			g.guides = append(g.guides, guide{from, onChord})
+			return onChord
+		}
+	}
+
+	if g.gridSpacing > 0 {
+		if !alignedX {
+			snapped.X = math.Round(p.X/g.gridSpacing) * g.gridSpacing
+		}
+		if !alignedY {
+			snapped.Y = math.Round(p.Y/g.gridSpacing) * g.gridSpacing
+		}
+	}
+	return snapped
+}
+
+// snapCurves returns the segments of the curve a knot dragged from index i
+// can snap to, which are all but those it's an end of. If the knot is being
+// added to the curve, added is set and i is the segment it splits.
+func (g *Game) snapCurves(i int, added bool) []*bezier.Bezier {
+	path := g.path()
+	curves, err := bezier.Segments(path.spline)
+	if err != nil {
+		return nil
+	}
+	var snapTo []*bezier.Bezier
+	for s, curve := range curves {
+		touches := s == i
+		if !added {
+			touches = s == i || (s+1)%len(path.Points) == i
+		}
+		if !touches {
+			snapTo = append(snapTo, curve)
+		}
+	}
+	return snapTo
+}
+
+// dragged reports whether the knot at index i is being dragged.
+func (g *Game) dragged(i int) bool {
+	for _, d := range g.drags {
+		if d.knot == i && d.handle == noHandle {
+			return true
+		}
+	}
+	return false
+}
+
+// previousKnot returns the index of the knot before the one at index i on
+// the curve, or the one after if it's the first knot of an open curve. It
+// returns -1 if there is neither.
+func (g *Game) previousKnot(i int) int {
+	path := g.path()
+	switch {
+	case i > 0:
+		return i - 1
+	case path.Closed && len(path.Points) > 1:
+		return len(path.Points) - 1
+	case len(path.Points) > 1:
+		return 1
+	}
+	return -1
+}
+
+// drawGrid draws the grid knots snap to, unless its lines would be too close
+// together on screen to be useful.
+func (g *Game) drawGrid(screen *ebiten.Image) {
+	step := g.gridSpacing * g.camera.zoom
+	if step < minGridPixels {
+		return
+	}
+	topLeft := g.camera.toWorld(0, 0)
+	bottomRight := g.camera.toWorld(screenWidth, screenHeight-toolbarHeight)
+	for x := math.Ceil(topLeft.X/g.gridSpacing) * g.gridSpacing; x <= bottomRight.X; x += g.gridSpacing {
+		sx := float32(g.camera.toScreen(bezier.Point{X: x}).X)
+		vector.StrokeLine(screen, sx, 0, sx, screenHeight-toolbarHeight, 1, gridColor, false)
+	}
+	for y := math.Ceil(topLeft.Y/g.gridSpacing) * g.gridSpacing; y <= bottomRight.Y; y += g.gridSpacing {
+		sy := float32(g.camera.toScreen(bezier.Point{Y: y}).Y)
+		vector.StrokeLine(screen, 0, sy, screenWidth, sy, 1, gridColor, false)
+	}
+}
+
+// drawGuides draws what the knots being dragged snapped to.
+func (g *Game) drawGuides(screen *ebiten.Image) {
+	for _, gd := range g.guides {
+		from, to := g.camera.toScreen(gd.from), g.camera.toScreen(gd.to)
+		if from == to {
+			vector.StrokeCircle(screen, float32(from.X), float32(from.Y), pointDiameter/2+2, 1, guideColor, true)
+			continue
+		}
+		vector.StrokeLine(screen, float32(from.X), float32(from.Y), float32(to.X), float32(to.Y), 1, guideColor, true)
+	}
+}
+
+// distance returns the distance between a and b.
+func distance(a, b bezier.Point) float64 {
+	return math.Hypot(a.X-b.X, a.Y-b.Y)
+}
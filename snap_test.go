@@ -0,0 +1,177 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/braheezy/hobby-spline/pkg/bezier"
+	"github.com/braheezy/hobby-spline/pkg/scene"
+)
+
+// newSnapGame returns a game with snapping on, whose path has a knot to drag
+// at index 3, whose previous knot is at 500, 100.
+func newSnapGame(gridSpacing float64) *Game {
+	g := newTestGame()
+	path := scene.DefaultPath()
+	path.Points = []scene.Knot{{X: 100, Y: 100}, {X: 300, Y: 120}, {X: 500, Y: 100}, {X: 300, Y: 400}, {X: 100, Y: 400}}
+	g.setScene(&scene.Scene{Version: scene.Version, Paths: []scene.Path{path}})
+	g.fitSplines()
+	g.snap = true
+	g.gridSpacing = gridSpacing
+	return g
+}
+
+// fromPrevious returns the point length from the knot before the dragged
+// one, in the direction degrees clockwise from the X axis on screen.
+func fromPrevious(degrees, length float64) bezier.Point {
+	rad := degrees * math.Pi / 180
+	return bezier.Point{X: 500 + length*math.Cos(rad), Y: 100 + length*math.Sin(rad)}
+}
+
+func TestSnapKnot(t *testing.T) {
+	curves := newSnapGame(0).snapCurves(3, false)
+	if len(curves) != 2 {
+		t.Fatalf("knot 3 can snap to %d segments, want the 2 it isn't an end of", len(curves))
+	}
+	// Close to the end of the first segment, where the curve is also within
+	// reach of the x of the knot there
+	nearEnd := curves[0].Get(0.99)
+	if math.Abs(nearEnd.X-300) > snapDistance/2 {
+		t.Fatalf("the first segment is at %v near its end, too far from x = 300 to test with", nearEnd)
+	}
+
+	onCurve := func(p bezier.Point) bezier.Point {
+		var nearest bezier.Point
+		for i, curve := range curves {
+			if projected, _ := curve.Project(p); i == 0 || distance(projected, p) < distance(nearest, p) {
+				nearest = projected
+			}
+		}
+		return nearest
+	}
+
+	tests := []struct {
+		name        string
+		gridSpacing float64
+		p, want     bezier.Point
+		// guides is the number of guides showing what the knot snapped to
+		guides int
+	}{
+		{
+			name:   "curve",
+			p:      bezier.Point{X: nearEnd.X, Y: nearEnd.Y + 5},
+			want:   onCurve(bezier.Point{X: nearEnd.X, Y: nearEnd.Y + 5}),
+			guides: 1,
+		},
+		{
+			// The curve beats lining up with the knot at x = 300, and the
+			// grid
+			name:        "curve before knot x",
+			gridSpacing: 50,
+			p:           bezier.Point{X: nearEnd.X + 2, Y: nearEnd.Y - 4},
+			want:        onCurve(bezier.Point{X: nearEnd.X + 2, Y: nearEnd.Y - 4}),
+			guides:      1,
+		},
+		{
+			name:   "knot x",
+			p:      bezier.Point{X: 304, Y: 250},
+			want:   bezier.Point{X: 300, Y: 250},
+			guides: 1,
+		},
+		{
+			name:   "knot x and y",
+			p:      bezier.Point{X: 505, Y: 395},
+			want:   bezier.Point{X: 500, Y: 400},
+			guides: 2,
+		},
+		{
+			// The chord from the previous knot is at 135°, but lining up
+			// with x = 300 stops it snapping to that angle
+			name:   "knot x before angle",
+			p:      fromPrevious(135, 280),
+			want:   bezier.Point{X: 300, Y: fromPrevious(135, 280).Y},
+			guides: 1,
+		},
+		{
+			name:        "knot x before grid",
+			gridSpacing: 50,
+			p:           bezier.Point{X: 104, Y: 262},
+			want:        bezier.Point{X: 100, Y: 250},
+			guides:      2,
+		},
+		{
+			name:   "angle",
+			p:      fromPrevious(121, 300),
+			want:   fromPrevious(120, 300),
+			guides: 1,
+		},
+		{
+			name:        "angle before grid",
+			gridSpacing: 50,
+			p:           fromPrevious(121, 300),
+			want:        fromPrevious(120, 300),
+			guides:      1,
+		},
+		{
+			name:        "grid",
+			gridSpacing: 50,
+			p:           fromPrevious(127.5, 300),
+			want:        bezier.Point{X: 300, Y: 350},
+		},
+		{
+			name: "nothing",
+			p:    fromPrevious(127.5, 300),
+			want: fromPrevious(127.5, 300),
+		},
+	}
+	for _, tc := range tests {
+		g := newSnapGame(tc.gridSpacing)
+		d := &drag{knot: 3, snapTo: g.snapCurves(3, false)}
+		if got := g.snapKnot(3, d, tc.p); distance(got, tc.want) > 1e-9 {
+			t.Errorf("%s: %v snapped to %v, want %v", tc.name, tc.p, got, tc.want)
+		}
+		if len(g.guides) != tc.guides {
+			t.Errorf("%s: %d guides, want %d", tc.name, len(g.guides), tc.guides)
+		}
+	}
+}
+
+func TestSnapAltOverride(t *testing.T) {
+	defer func(pressed func() bool) { altPressed = pressed }(altPressed)
+
+	// Dragging knot 3 to where the chord from the one before it is 1° off
+	// a round angle
+	to := fromPrevious(121, 300)
+	tests := []struct {
+		name      string
+		snap, alt bool
+		want      bezier.Point
+	}{
+		{name: "snapping", snap: true, want: fromPrevious(120, 300)},
+		{name: "holding alt", snap: true, alt: true, want: to},
+		{name: "snapping off", want: to},
+		{name: "snapping off and holding alt", alt: true, want: to},
+	}
+	for _, tc := range tests {
+		g := newSnapGame(0)
+		g.snap = tc.snap
+		altPressed = func() bool { return tc.alt }
+		if g.snapping() != (tc.snap && !tc.alt) {
+			t.Errorf("%s: snapping is %v", tc.name, g.snapping())
+		}
+
+		// Drag from the knot by whole pixels, with the rest of the way
+		// made up by where the drag started
+		k := g.path().Points[3]
+		x, y := int(math.Round(to.X)), int(math.Round(to.Y))
+		g.tick(press(mousePointer, int(k.X), int(k.Y)))
+		g.drags[mousePointer].offsetX += float64(x) - to.X
+		g.drags[mousePointer].offsetY += float64(y) - to.Y
+		g.tick(move(mousePointer, x, y))
+		g.tick(release(mousePointer, x, y))
+
+		if got := g.path().Points[3]; distance(bezier.Point{X: got.X, Y: got.Y}, tc.want) > 1e-9 {
+			t.Errorf("%s: dragged the knot to %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"math"
+
 	catppuccin "github.com/catppuccin/go"
 	"github.com/charmbracelet/lipgloss"
 	"golang.org/x/image/font/inconsolata"
@@ -15,15 +17,70 @@ const (
 	sliderHeight       = 10
 	sliderKnobDiameter = 20
 	toggleDiameter     = 20
+	toggleRadius       = toggleDiameter / 2
+	widgetHeight       = 24
+	widgetGap          = 12
+	dropdownWidth      = 100
 	pointDiameter      = 10
+	handleDiameter     = 6
+	inspectorWidth     = 200
+	pathListWidth      = 140
+	swatchSize         = 12
+	// Distance in pixels from the selected knots to the gizmo around them,
+	// and from its top to the handle rotating them
+	gizmoMargin          = 16
+	rotateHandleDistance = 24
+
+	// Fewest knots the curve can be left with, so there's always a curve to draw
+	minKnots = 3
+	// Distance in pixels from the middle of the view to the ends of new paths
+	newPathSize = 80
+
+	// Distance in pixels within which clicks grab a knot or insert one on the curve
+	knotGrabRadius    = 25
+	handleGrabRadius  = 10
+	curveGrabDistance = 10
+	// Ticks a touch must be held still on a knot to delete it, and how far it may wander
+	longPressTicks    = 30
+	longPressDistance = 8
+	// Ticks a key must be held before it repeats, and between repeats
+	keyRepeatDelay    = 24
+	keyRepeatInterval = 4
+	// Distances in pixels the arrow keys nudge knots, without and with Shift
+	nudgeStep    = 1
+	farNudgeStep = 10
+	// Change in omega each press of the bracket keys makes
+	omegaStep = 0.05
+
+	// Distance in pixels within which dragged knots snap to the curve and to
+	// line up with other knots, and the angles chords snap to
+	snapDistance = 8
+	snapAngle    = math.Pi / 12
+	// Grid spacing used unless -grid says otherwise, and the closest its
+	// lines are drawn together on screen
+	defaultGridSpacing = 20
+	minGridPixels      = 6
 
-	sliderX        = (screenWidth - sliderWidth) / 4
-	sliderY        = screenHeight - toolbarHeight/2 - sliderHeight/2
-	combToggleX    = sliderX + sliderWidth + 60
-	combToggleY    = screenHeight - toolbarHeight/2
-	naturalToggleX = combToggleX + toggleDiameter + 120
-	naturalToggleY = combToggleY
-	toggleRadius   = toggleDiameter / 2
+	// Limits on how far the view can be zoomed, in screen pixels per unit
+	minZoom = 0.1
+	maxZoom = 20
+	// Zoom applied per notch of the mouse wheel
+	wheelZoomFactor = 1.1
+
+	// Maximum distance in pixels between a freehand stroke and its fitted curve
+	fitTolerance = 4
+	// Strokes turning sharper than this (in radians) are split into separate curves
+	fitCornerAngle = math.Pi / 3
+
+	// Glyphs shown in glyph mode, from the Go Regular font
+	firstGlyph = 'g'
+	minGlyph   = '!'
+	maxGlyph   = '~'
+
+	// File name used when exporting the curve with the S key
+	svgExportName = "hobby-spline.svg"
+	// Scene file saved and loaded with Ctrl+S and Ctrl+O, unless -scene says otherwise
+	sceneFileName = "hobby-spline.json"
 )
 
 var (
@@ -138,17 +195,28 @@ var (
 
 	textFont = inconsolata.Regular8x16
 
-	backgroundColor   = overlay0
-	toolbarColor      = mauve
-	sliderBgColor     = surface1
-	sliderKnobColor   = sky
-	toggleOnColor     = sky
-	toggleOffColor    = maroon
-	textColor         = crust
-	pointColor        = sky
-	outlineColor      = sliderBgColor
-	curveColor        = toolbarColor
-	naturalCurveColor = overlay2
+	backgroundColor    = overlay0
+	toolbarColor       = mauve
+	sliderBgColor      = surface1
+	sliderKnobColor    = sky
+	toggleOnColor      = sky
+	toggleOffColor     = maroon
+	textColor          = crust
+	pointColor         = sky
+	outlineColor       = sliderBgColor
+	curveColor         = toolbarColor
+	naturalCurveColor  = overlay2
+	handleColor        = lavender
+	explicitColor      = peach
+	panelColor         = toolbarColor
+	buttonColor        = surface0
+	buttonPressedColor = surface2
+	gridColor          = surface2
+	guideColor         = green
+	gizmoColor         = sapphire
+
+	// Colors given to paths in turn as they're added
+	pathColors = []lipgloss.AdaptiveColor{curveColor, blue, peach, green, pink, teal}
 
 	padding = sliderKnobDiameter
 )
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"slices"
+
+	"github.com/braheezy/hobby-spline/pkg/scene"
+)
+
+// updateToolbar declares the widgets along the bottom of the window, and
+// the list of paths when they're being edited, and applies whatever the user
+// did with them.
+func (g *Game) updateToolbar() {
+	g.ui.panel(image.Rect(0, screenHeight-toolbarHeight, screenWidth, screenHeight), toolbarColor)
+	r := row{x: padding, y: screenHeight - toolbarHeight + (toolbarHeight-widgetHeight)/2, height: widgetHeight}
+
+	path := g.path()
+	g.ui.label(r.next(textWidth("w = 0.00", textFont)), fmt.Sprintf("w = %.2f", path.Omega))
+	started, finished := g.ui.slider("omega", r.next(sliderWidth), &path.Omega)
+	if started {
+		g.omegaStart = path.Omega
+	}
+	if finished && path.Omega != g.omegaStart {
+		g.history.push(&setOmega{path: path, from: g.omegaStart, to: path.Omega})
+	}
+
+	if g.ui.toggle("comb", r.next(toggleWidth("Show Comb")), "Show Comb", g.showComb) {
+		view := g.view()
+		view.ShowComb = !view.ShowComb
+		g.apply(&setView{from: g.view(), to: view})
+	}
+	if g.ui.toggle("natural", r.next(toggleWidth("Show Natural")), "Show Natural", g.showNatural) {
+		view := g.view()
+		view.ShowNatural = !view.ShowNatural
+		g.apply(&setView{from: g.view(), to: view})
+	}
+
+	// Natural splines can't be closed
+	algorithms := []string{scene.AlgorithmHobby, scene.AlgorithmNatural, scene.AlgorithmMetaPost}
+	if path.Closed {
+		algorithms = []string{scene.AlgorithmHobby, scene.AlgorithmMetaPost}
+	}
+	if i, changed := g.ui.dropdown("algorithm", r.next(dropdownWidth), algorithms, slices.Index(algorithms, path.Algorithm)); changed {
+		g.setAlgorithm(algorithms[i])
+	}
+
+	if g.ui.toggle("snap", r.next(toggleWidth("Snap")), "Snap", g.snap) {
+		g.snap = !g.snap
+	}
+
+	if g.ui.button("fit", r.next(textWidth("Fit", textFont)+widgetGap), "Fit") {
+		g.fitToContent()
+	}
+
+	if !g.freehand && !g.glyphMode {
+		g.updatePathList()
+		g.updateSelectionButtons()
+	}
+}
+
+// setAlgorithm changes the algorithm fitting the current path to its knots.
+// Only MetaPost honours the constraints on knots, so switching to another
+// algorithm removes them, as a scene can't have them on other paths.
+// Undoing the switch brings them back.
+func (g *Game) setAlgorithm(algorithm string) {
+	path := g.path()
+	to := clonePath(path.Path)
+	to.Algorithm = algorithm
+	if algorithm != scene.AlgorithmMetaPost {
+		to.Points = pruneConstraints(to.Points)
+	}
+	g.apply(&setPath{path: path, from: path.Path, to: to})
+}
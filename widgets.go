@@ -0,0 +1,301 @@
+package main
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// ui is a small immediate-mode widget layer. Widgets are declared afresh
+// every tick between begin and the next begin: each call hit-tests the
+// widget against the pointer, reports what the user did to it, and queues
+// the widget to be drawn by draw.
+//
+// Widgets are identified by a string id, which must be unique among the
+// widgets declared in a tick. The pointer is the mouse or the first touch.
+type ui struct {
+	x, y        int
+	down        bool
+	justPressed bool
+
+	// hot is the widget under the pointer, and active the one the pointer
+	// was pressed on, which keeps the pointer until it's released
+	hot    string
+	active string
+	// open is the dropdown whose options are showing, and overlay where
+	// they were drawn last tick, which hides the widgets underneath
+	open    string
+	overlay image.Rectangle
+	// areas are where the widgets and panels declared this tick are
+	areas []image.Rectangle
+
+	draws []func(*ebiten.Image)
+}
+
+// begin starts a new tick with the current state of the pointer.
+func (u *ui) begin(x, y int, down, justPressed bool) {
+	u.x, u.y = x, y
+	u.down, u.justPressed = down, justPressed
+	u.hot = ""
+	u.areas = u.areas[:0]
+	u.draws = u.draws[:0]
+	if u.open == "" {
+		u.overlay = image.Rectangle{}
+	}
+}
+
+// draw draws the widgets declared this tick, in the order they were
+// declared.
+func (u *ui) draw(screen *ebiten.Image) {
+	for _, d := range u.draws {
+		d(screen)
+	}
+}
+
+// capturing reports whether a pointer at x, y is, or would be, used by a
+// widget, in which case it shouldn't also edit the canvas underneath.
+func (u *ui) capturing(x, y int) bool {
+	if u.active != "" {
+		return true
+	}
+	for _, r := range u.areas {
+		if image.Pt(x, y).In(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// over reports whether the pointer is over r, and not over an open dropdown
+// covering it.
+func (u *ui) over(r image.Rectangle) bool {
+	p := image.Pt(u.x, u.y)
+	return p.In(r) && !p.In(u.overlay)
+}
+
+// interact hit-tests the widget id occupying r, reporting whether the
+// pointer is over it, and whether it was clicked, i.e. pressed and released
+// without leaving it.
+func (u *ui) interact(id string, r image.Rectangle) (over bool, clicked bool) {
+	u.areas = append(u.areas, r)
+	over = u.over(r)
+	if over {
+		u.hot = id
+		if u.justPressed && u.active == "" {
+			u.active = id
+		}
+	}
+	if u.active == id && !u.down {
+		u.active = ""
+		clicked = over
+	}
+	return over, clicked
+}
+
+// state returns how a widget should look.
+func (u *ui) state(id string) widgetState {
+	switch {
+	case u.active == id:
+		return widgetPressed
+	case u.hot == id:
+		return widgetHovered
+	}
+	return widgetIdle
+}
+
+type widgetState int
+
+const (
+	widgetIdle widgetState = iota
+	widgetHovered
+	widgetPressed
+)
+
+// outline returns the width of the outline drawn around a widget.
+func (s widgetState) outline() float32 {
+	if s == widgetIdle {
+		return 1
+	}
+	return 2
+}
+
+// panel fills r, and stops the pointer reaching the canvas beneath it.
+func (u *ui) panel(r image.Rectangle, clr color.Color) {
+	u.areas = append(u.areas, r)
+	u.draws = append(u.draws, func(screen *ebiten.Image) {
+		vector.DrawFilledRect(screen, float32(r.Min.X), float32(r.Min.Y), float32(r.Dx()), float32(r.Dy()), clr, true)
+	})
+}
+
+// label draws s vertically centered in r.
+func (u *ui) label(r image.Rectangle, s string) {
+	u.draws = append(u.draws, func(screen *ebiten.Image) {
+		drawText(screen, s, r.Min.X, r.Min.Y+r.Dy()/2)
+	})
+}
+
+// button draws a button and reports whether it was clicked.
+func (u *ui) button(id string, r image.Rectangle, s string) bool {
+	_, clicked := u.interact(id, r)
+	state := u.state(id)
+	u.draws = append(u.draws, func(screen *ebiten.Image) {
+		fill := buttonColor
+		if state == widgetPressed {
+			fill = buttonPressedColor
+		}
+		drawBox(screen, r, fill, state)
+		drawText(screen, s, r.Min.X+(r.Dx()-textWidth(s, textFont))/2, r.Min.Y+r.Dy()/2)
+	})
+	return clicked
+}
+
+// listItem draws an item of a list, marked with a swatch of clr and shown
+// pressed while it's selected, and reports whether it was clicked.
+func (u *ui) listItem(id string, r image.Rectangle, s string, clr color.Color, selected bool) bool {
+	_, clicked := u.interact(id, r)
+	state := u.state(id)
+	u.draws = append(u.draws, func(screen *ebiten.Image) {
+		fill := buttonColor
+		if selected || state == widgetPressed {
+			fill = buttonPressedColor
+		}
+		drawBox(screen, r, fill, state)
+		swatch := float32(r.Min.Y + (r.Dy()-swatchSize)/2)
+		vector.DrawFilledRect(screen, float32(r.Min.X+widgetGap/2), swatch, swatchSize, swatchSize, clr, true)
+		drawText(screen, s, r.Min.X+widgetGap+swatchSize, r.Min.Y+r.Dy()/2)
+	})
+	return clicked
+}
+
+// toggle draws a switch, on or off, followed by its label, and reports
+// whether it was clicked. The caller decides what clicking it does.
+func (u *ui) toggle(id string, r image.Rectangle, s string, on bool) bool {
+	_, clicked := u.interact(id, r)
+	state := u.state(id)
+	u.draws = append(u.draws, func(screen *ebiten.Image) {
+		clr := toggleOffColor
+		if on {
+			clr = toggleOnColor
+		}
+		cx, cy := float32(r.Min.X+toggleRadius), float32(r.Min.Y+r.Dy()/2)
+		vector.DrawFilledCircle(screen, cx, cy, toggleRadius, clr, true)
+		vector.StrokeCircle(screen, cx, cy, toggleRadius, state.outline(), outlineColor, true)
+		drawText(screen, s, r.Min.X+toggleDiameter+widgetGap/2, r.Min.Y+r.Dy()/2)
+	})
+	return clicked
+}
+
+// toggleWidth returns the width of a toggle labelled s.
+func toggleWidth(s string) int {
+	return toggleDiameter + widgetGap/2 + textWidth(s, textFont)
+}
+
+// slider draws a slider along r for a value between 0 and 1, which pressing
+// and dragging anywhere along it changes. It reports when a drag starts and
+// when it finishes, so the change can be recorded as a whole.
+func (u *ui) slider(id string, r image.Rectangle, value *float64) (started bool, finished bool) {
+	wasActive := u.active == id
+	u.interact(id, r)
+	if u.active == id {
+		v := float64(u.x-r.Min.X) / float64(r.Dx())
+		*value = min(1, max(0, v))
+	}
+	started = !wasActive && u.active == id
+	finished = wasActive && u.active != id
+	state := u.state(id)
+
+	v := *value
+	u.draws = append(u.draws, func(screen *ebiten.Image) {
+		cy := float32(r.Min.Y + r.Dy()/2)
+		vector.DrawFilledRect(screen, float32(r.Min.X), cy-sliderHeight/2, float32(r.Dx()), sliderHeight, sliderBgColor, true)
+		knobX := float32(r.Min.X) + float32(v)*float32(r.Dx())
+		vector.DrawFilledCircle(screen, knobX, cy, sliderKnobDiameter/2, sliderKnobColor, true)
+		vector.StrokeCircle(screen, knobX, cy, sliderKnobDiameter/2, state.outline(), outlineColor, true)
+	})
+	return started, finished
+}
+
+// dropdown draws the selected one of options, which, when clicked, shows
+// the rest above it to choose from. It returns the option chosen, and
+// whether it's a different one.
+func (u *ui) dropdown(id string, r image.Rectangle, options []string, selected int) (int, bool) {
+	choice := selected
+	if u.open == id {
+		// The options open upwards, since the toolbar is at the bottom
+		lineHeight := r.Dy()
+		list := image.Rect(r.Min.X, r.Min.Y-len(options)*lineHeight, r.Max.X, r.Min.Y)
+		u.areas = append(u.areas, list, r)
+		hovered := -1
+		if p := image.Pt(u.x, u.y); p.In(list) {
+			hovered = (p.Y - list.Min.Y) / lineHeight
+		}
+		if u.justPressed {
+			if hovered >= 0 {
+				choice = hovered
+			}
+			// Any press closes the options, and the one that does so
+			// isn't passed on to whatever is under it
+			u.open = ""
+			u.active = id
+		}
+		u.overlay = list
+
+		u.draws = append(u.draws, func(screen *ebiten.Image) {
+			drawBox(screen, list, buttonColor, widgetIdle)
+			for i, option := range options {
+				row := image.Rect(list.Min.X, list.Min.Y+i*lineHeight, list.Max.X, list.Min.Y+(i+1)*lineHeight)
+				if i == hovered {
+					drawBox(screen, row, buttonPressedColor, widgetHovered)
+				}
+				drawText(screen, option, row.Min.X+widgetGap/2, row.Min.Y+lineHeight/2)
+			}
+		})
+	} else if _, clicked := u.interact(id, r); clicked {
+		u.open = id
+	}
+	if u.active == id && !u.down {
+		u.active = ""
+	}
+
+	state := u.state(id)
+	label := ""
+	if choice >= 0 && choice < len(options) {
+		label = options[choice]
+	}
+	u.draws = append(u.draws, func(screen *ebiten.Image) {
+		drawBox(screen, r, buttonColor, state)
+		drawText(screen, label+" ^", r.Min.X+widgetGap/2, r.Min.Y+r.Dy()/2)
+	})
+	return choice, choice != selected
+}
+
+// row lays out widgets from left to right, all the same height.
+type row struct {
+	x, y   int
+	height int
+}
+
+// next returns where the next widget goes, given its width.
+func (r *row) next(width int) image.Rectangle {
+	rect := image.Rect(r.x, r.y, r.x+width, r.y+r.height)
+	r.x += width + widgetGap
+	return rect
+}
+
+// drawBox fills r and outlines it according to state.
+func drawBox(screen *ebiten.Image, r image.Rectangle, fill color.Color, state widgetState) {
+	x, y, w, h := float32(r.Min.X), float32(r.Min.Y), float32(r.Dx()), float32(r.Dy())
+	vector.DrawFilledRect(screen, x, y, w, h, fill, true)
+	vector.StrokeRect(screen, x, y, w, h, state.outline(), outlineColor, true)
+}
+
+// drawText draws s starting at x, vertically centered on y.
+func drawText(screen *ebiten.Image, s string, x, y int) {
+	textOp := &text.DrawOptions{}
+	textOp.ColorScale.ScaleWithColor(textColor)
+	textOp.GeoM.Translate(float64(x), float64(y-textFont.Metrics().Height.Ceil()/2))
+	text.Draw(screen, s, text.NewGoXFace(textFont), textOp)
+}